@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/szerookii/goquobuz/qobuz"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const defaultProfile = "default"
+
+type Profile struct {
+	Email          string
+	Password       string `json:",omitempty"`
+	PasswordHash   string `json:",omitempty"`
+	Token          string `json:",omitempty"`
+	DownloadFolder string
+	// Quality is the preferred format, 0 picks the best one the account allows.
+	Quality types.Quality `json:",omitempty"`
+	// NamingTemplate names downloaded files, see trackFilename.
+	NamingTemplate string `json:",omitempty"`
+	// Concurrency is the number of album tracks downloaded at the same time.
+	Concurrency int `json:",omitempty"`
+}
+
+type Config struct {
+	Version        int
+	CurrentProfile string
+	Profiles       map[string]*Profile
+	AppID          string `json:",omitempty"`
+	AppSecret      string `json:",omitempty"`
+}
+
+// legacyConfig is the single account config.json written by older versions.
+type legacyConfig struct {
+	Email          string
+	Password       string
+	PasswordHash   string
+	Token          string
+	DownloadFolder string
+	AppID          string
+	AppSecret      string
+}
+
+func newProfile() *Profile {
+	return &Profile{DownloadFolder: defaultDownloadFolder()}
+}
+
+func clientOptions(config *Config) []qobuz.Option {
+	opts := []qobuz.Option{
+		qobuz.WithAppCredentialsStore(qobuz.NewFileAppCredentialsStore(filepath.Join(cacheDir, "app_credentials.json"))),
+	}
+	if config.AppID != "" && config.AppSecret != "" {
+		opts = append(opts, qobuz.WithAppCredentials(config.AppID, config.AppSecret))
+	}
+
+	return opts
+}
+
+// readConfig reads, upgrades and validates the config, creating it when it
+// does not exist and saving it after an upgrade. Validation errors are joined
+// *fieldError values.
+func readConfig() (*Config, error) {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		fmt.Println("Config file does not exist, creating a new one...")
+
+		config := &Config{}
+		config.applyDefaults()
+
+		if err := writeConfig(configPath, config); err != nil {
+			return nil, fmt.Errorf("failed to create empty config file: %v", err)
+		}
+
+		return config, nil
+	}
+
+	config, upgraded, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if upgraded {
+		if err := writeConfig(configPath, config); err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// loadConfig is readConfig without any write, for config validate. upgraded
+// tells whether the file uses an older schema.
+func loadConfig() (*Config, bool, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	config, upgraded, err := decodeConfig(data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	config.applyDefaults()
+
+	if err := config.validate(); err != nil {
+		return nil, false, err
+	}
+
+	return config, upgraded, nil
+}
+
+func writeConfig(configPath string, config *Config) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return errors.New("failed to marshal config to JSON")
+	}
+
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %v", err)
+	}
+
+	// Configs written by older versions were world readable.
+	return os.Chmod(configPath, 0600)
+}
+
+// profile returns the profile called name, or the current one when name is
+// empty.
+func (c *Config) profile(name string) (string, *Profile, error) {
+	if name == "" {
+		name = c.CurrentProfile
+	}
+
+	if name == "" {
+		name = defaultProfile
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return "", nil, fmt.Errorf("profile %q does not exist", name)
+	}
+
+	return name, profile, nil
+}
+
+func (c *Config) profileNames() []string {
+	var names []string
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
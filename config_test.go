@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigDoesNotWrite(t *testing.T) {
+	configPath = filepath.Join(t.TempDir(), "config.json")
+
+	if _, _, err := loadConfig(); err == nil {
+		t.Fatal("loadConfig succeeded without a config file")
+	}
+
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Fatalf("loadConfig created the config: %v", err)
+	}
+
+	legacy := []byte(`{"Email":"user@example.com","DownloadFolder":"/music"}`)
+	if err := os.WriteFile(configPath, legacy, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, upgraded, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !upgraded || config.Version != configVersion {
+		t.Errorf("upgraded = %v, version = %d; want an upgrade to %d", upgraded, config.Version, configVersion)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != string(legacy) {
+		t.Errorf("loadConfig rewrote the config:\n%s", data)
+	}
+}
+
+func TestUnknownKeysAreSorted(t *testing.T) {
+	data := []byte(`{"Version":2,"CurrentProfile":"a","Profiles":{
+		"c":{"Email":"c@example.com","Colour":1},
+		"a":{"Email":"a@example.com","Colour":1},
+		"b":{"Email":"b@example.com","Colour":1,"Bitrate":1}
+	}}`)
+
+	want := []string{
+		"Profiles.a.Colour: unknown key",
+		"Profiles.b.Bitrate: unknown key",
+		"Profiles.b.Colour: unknown key",
+		"Profiles.c.Colour: unknown key",
+	}
+
+	// Go randomizes map iteration, a few runs would catch an unsorted walk.
+	for i := 0; i < 10; i++ {
+		_, _, err := decodeConfig(data)
+
+		joined, ok := err.(interface{ Unwrap() []error })
+		if !ok {
+			t.Fatalf("decodeConfig error = %v, want joined field errors", err)
+		}
+
+		var got []string
+		for _, err := range joined.Unwrap() {
+			var fieldErr *fieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			got = append(got, err.Error())
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("errors = %q, want %q", got, want)
+		}
+
+		for j := range want {
+			if got[j] != want[j] {
+				t.Fatalf("errors = %q, want %q", got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// configVersion is the current config schema:
+//
+//	1: a single account with Email, Password and DownloadFolder at the top.
+//	2: named profiles, with an explicit Version since this release.
+const configVersion = 2
+
+const maxConcurrency = 16
+
+var (
+	placeholderRegex  = regexp.MustCompile(`\{[^{}]*\}`)
+	passwordHashRegex = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+var namingPlaceholders = map[string]bool{
+	"{title}":        true,
+	"{artist}":       true,
+	"{album}":        true,
+	"{track_number}": true,
+	"{disc_number}":  true,
+}
+
+// fieldError points at the config field that is wrong, e.g.
+// Profiles.studio.Quality.
+type fieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *fieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// decodeConfig decodes any supported version of the config, rejecting
+// unknown keys, and upgrades it to configVersion. It reports whether an
+// upgrade happened.
+func decodeConfig(data []byte) (*Config, bool, error) {
+	var header struct {
+		Version  int
+		Profiles json.RawMessage
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, false, fmt.Errorf("failed to parse config file: %v", err)
+	}
+
+	version := header.Version
+	if version == 0 {
+		version = 1
+		if header.Profiles != nil {
+			version = 2
+		}
+	}
+
+	if version > configVersion {
+		return nil, false, fmt.Errorf("config version %d is newer than this goqobuz supports (%d)", version, configVersion)
+	}
+
+	if version == 1 {
+		var legacy legacyConfig
+		if err := decodeStrict(data, &legacy); err != nil {
+			return nil, false, err
+		}
+
+		return upgradeLegacyConfig(&legacy), true, nil
+	}
+
+	var config Config
+	if err := decodeStrict(data, &config); err != nil {
+		return nil, false, err
+	}
+
+	upgraded := config.Version != configVersion
+	config.Version = configVersion
+
+	return &config, upgraded, nil
+}
+
+func upgradeLegacyConfig(legacy *legacyConfig) *Config {
+	return &Config{
+		Version:        configVersion,
+		CurrentProfile: defaultProfile,
+		Profiles: map[string]*Profile{defaultProfile: {
+			Email:          legacy.Email,
+			Password:       legacy.Password,
+			PasswordHash:   legacy.PasswordHash,
+			Token:          legacy.Token,
+			DownloadFolder: legacy.DownloadFolder,
+		}},
+		AppID:     legacy.AppID,
+		AppSecret: legacy.AppSecret,
+	}
+}
+
+// decodeStrict reports every unknown key with its path before decoding.
+func decodeStrict(data []byte, v interface{}) error {
+	if errs := unknownKeys(data, reflect.TypeOf(v).Elem(), ""); len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to parse config file: %v", err)
+	}
+
+	return nil
+}
+
+func unknownKeys(data []byte, t reflect.Type, path string) []error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var errs []error
+	switch t.Kind() {
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(data, &fields) != nil {
+			return nil
+		}
+
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			field, ok := structField(t, key)
+			if !ok {
+				errs = append(errs, &fieldError{Field: joinPath(path, key), Message: "unknown key"})
+				continue
+			}
+
+			errs = append(errs, unknownKeys(fields[key], field.Type, joinPath(path, field.Name))...)
+		}
+	case reflect.Map:
+		var entries map[string]json.RawMessage
+		if json.Unmarshal(data, &entries) != nil {
+			return nil
+		}
+
+		keys := make([]string, 0, len(entries))
+		for key := range entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			errs = append(errs, unknownKeys(entries[key], t.Elem(), joinPath(path, key))...)
+		}
+	}
+
+	return errs
+}
+
+// structField finds the field encoding/json would decode key into.
+func structField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// applyDefaults fills what an older or hand written config may lack.
+func (c *Config) applyDefaults() {
+	c.Version = configVersion
+
+	if len(c.Profiles) == 0 {
+		c.Profiles = map[string]*Profile{defaultProfile: newProfile()}
+	}
+
+	for _, profile := range c.Profiles {
+		if profile != nil && profile.DownloadFolder == "" {
+			profile.DownloadFolder = defaultDownloadFolder()
+		}
+	}
+
+	if c.CurrentProfile == "" {
+		c.CurrentProfile = c.profileNames()[0]
+	}
+}
+
+// validate returns every problem found, joined, each one a *fieldError.
+func (c *Config) validate() error {
+	var errs []error
+
+	if _, ok := c.Profiles[c.CurrentProfile]; !ok {
+		errs = append(errs, &fieldError{Field: "CurrentProfile", Message: fmt.Sprintf("profile %q does not exist", c.CurrentProfile)})
+	}
+
+	if (c.AppID == "") != (c.AppSecret == "") {
+		errs = append(errs, &fieldError{Field: "AppID", Message: "AppID and AppSecret must be set together"})
+	}
+
+	for _, name := range c.profileNames() {
+		path := "Profiles." + name
+		if !profileNameRegex.MatchString(name) {
+			errs = append(errs, &fieldError{Field: path, Message: "profile names may only contain letters, digits, - and _"})
+		}
+
+		errs = append(errs, validateProfile(path, c.Profiles[name])...)
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateProfile(path string, profile *Profile) []error {
+	if profile == nil {
+		return []error{&fieldError{Field: path, Message: "must be an object"}}
+	}
+
+	var errs []error
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, &fieldError{Field: path + "." + field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if profile.Email != "" && !strings.Contains(profile.Email, "@") {
+		fail("Email", "%q is not an email address", profile.Email)
+	}
+
+	if profile.PasswordHash != "" && !passwordHashRegex.MatchString(profile.PasswordHash) {
+		fail("PasswordHash", "must be the MD5 hex digest of the password")
+	}
+
+	if info, err := os.Stat(profile.DownloadFolder); err == nil && !info.IsDir() {
+		fail("DownloadFolder", "%s is not a directory", profile.DownloadFolder)
+	} else if err != nil && !os.IsNotExist(err) {
+		fail("DownloadFolder", "%v", err)
+	}
+
+	switch profile.Quality {
+	case 0, types.MP3, types.CD16_44, types.HiRes24_96, types.HiRes24_192:
+	default:
+		fail("Quality", "unknown format ID %d, must be 5, 6, 7 or 27", profile.Quality)
+	}
+
+	for _, placeholder := range placeholderRegex.FindAllString(profile.NamingTemplate, -1) {
+		if !namingPlaceholders[placeholder] {
+			fail("NamingTemplate", "unknown placeholder %s", placeholder)
+		}
+	}
+
+	if profile.Concurrency < 0 || profile.Concurrency > maxConcurrency {
+		fail("Concurrency", "must be between 1 and %d, or 0 to download one track at a time", maxConcurrency)
+	}
+
+	return errs
+}
+
+// printConfigErrors prints one line per problem found by validate.
+func printConfigErrors(err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			fmt.Println("  " + err.Error())
+		}
+		return
+	}
+
+	fmt.Println("  " + err.Error())
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/charmbracelet/huh"
+	"github.com/szerookii/goquobuz/internal/keyring"
+	"github.com/szerookii/goquobuz/qobuz"
+	"os"
+)
+
+const keyringService = "goqobuz"
+
+func openKeyring() keyring.Store {
+	return keyring.Default(credentialsPath, keyringPassphrase)
+}
+
+// keyringPassphrase unlocks the encrypted file used when the system has no
+// keyring. GOQOBUZ_PASSPHRASE avoids the prompt on unattended machines.
+func keyringPassphrase() (string, error) {
+	if passphrase := os.Getenv("GOQOBUZ_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+
+	var passphrase string
+	if err := huh.NewInput().Title("Enter your keyring passphrase").Description("It encrypts the Qobuz credentials saved on this machine.").EchoMode(huh.EchoModePassword).Value(&passphrase).Run(); err != nil {
+		return "", err
+	}
+
+	return passphrase, nil
+}
+
+func getSecret(secrets keyring.Store, profile, key string) (string, error) {
+	secret, err := secrets.Get(keyringService, profile+"/"+key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", nil
+	}
+
+	return secret, err
+}
+
+func deleteSecrets(secrets keyring.Store, profile string) error {
+	for _, key := range []string{"password_hash", "token", "session"} {
+		if err := secrets.Delete(keyringService, profile+"/"+key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// keyringSessionStore keeps a profile's qobuz.Session in the keyring.
+type keyringSessionStore struct {
+	secrets keyring.Store
+	profile string
+}
+
+func (k *keyringSessionStore) Load() (*qobuz.Session, error) {
+	data, err := getSecret(k.secrets, k.profile, "session")
+	if err != nil || data == "" {
+		return nil, err
+	}
+
+	var session qobuz.Session
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (k *keyringSessionStore) Save(session *qobuz.Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	return k.secrets.Set(keyringService, k.profile+"/session", string(data))
+}
+
+func (k *keyringSessionStore) Clear() error {
+	return k.secrets.Delete(keyringService, k.profile+"/session")
+}
+
+// migrateSecrets moves passwords and tokens found in the config, and the
+// session files of older versions, into the keyring.
+func migrateSecrets(config *Config, secrets keyring.Store) error {
+	changed := false
+	for name, profile := range config.Profiles {
+		passwordHash := profile.PasswordHash
+		if passwordHash == "" && profile.Password != "" {
+			passwordHash = qobuz.HashPassword(profile.Password)
+		}
+
+		if passwordHash != "" {
+			if err := secrets.Set(keyringService, name+"/password_hash", passwordHash); err != nil {
+				return err
+			}
+		}
+
+		if profile.Token != "" {
+			if err := secrets.Set(keyringService, name+"/token", profile.Token); err != nil {
+				return err
+			}
+		}
+
+		if profile.Password != "" || profile.PasswordHash != "" || profile.Token != "" {
+			profile.Password, profile.PasswordHash, profile.Token = "", "", ""
+			changed = true
+		}
+
+		files := qobuz.NewFileSessionStore(sessionPath(name))
+		session, err := files.Load()
+		if err != nil {
+			return err
+		}
+
+		if session != nil {
+			if err := (&keyringSessionStore{secrets: secrets, profile: name}).Save(session); err != nil {
+				return err
+			}
+
+			if err := files.Clear(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return writeConfig(configPath, config)
+}
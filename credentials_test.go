@@ -0,0 +1,63 @@
+package main
+
+import (
+	"github.com/szerookii/goquobuz/internal/keyring"
+	"github.com/szerookii/goquobuz/qobuz"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateSecrets(t *testing.T) {
+	configPath = filepath.Join(t.TempDir(), "config.json")
+	stateDir = t.TempDir()
+
+	config := &Config{
+		Version:        configVersion,
+		CurrentProfile: "default",
+		Profiles: map[string]*Profile{
+			"default": {Email: "user@example.com", Password: "password", Token: "token-1"},
+			"studio":  {Email: "studio@example.com", PasswordHash: "5f4dcc3b5aa765d61d8327deb882cf99"},
+		},
+	}
+
+	if err := qobuz.NewFileSessionStore(sessionPath("studio")).Save(&qobuz.Session{UserAuthToken: "token-2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := keyring.NewMemory()
+	if err := migrateSecrets(config, secrets); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []struct{ profile, key, value string }{
+		{"default", "password_hash", qobuz.HashPassword("password")},
+		{"default", "token", "token-1"},
+		{"studio", "password_hash", "5f4dcc3b5aa765d61d8327deb882cf99"},
+	} {
+		if value, err := getSecret(secrets, want.profile, want.key); err != nil || value != want.value {
+			t.Errorf("%s/%s = %q, %v; want %q", want.profile, want.key, value, err, want.value)
+		}
+	}
+
+	session, err := (&keyringSessionStore{secrets: secrets, profile: "studio"}).Load()
+	if err != nil || session == nil || session.UserAuthToken != "token-2" {
+		t.Errorf("studio session = %+v, %v; want token-2", session, err)
+	}
+
+	if _, err := os.Stat(sessionPath("studio")); !os.IsNotExist(err) {
+		t.Errorf("the session file was not removed: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{"password", "token-1", "5f4dcc3b5aa765d61d8327deb882cf99"} {
+		if strings.Contains(string(data), `"`+secret+`"`) {
+			t.Errorf("%q is still in the config", secret)
+		}
+	}
+}
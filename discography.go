@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/huh/spinner"
+	"github.com/szerookii/goquobuz/qobuz"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"regexp"
+	"strings"
+)
+
+const (
+	releaseAlbum       = "album"
+	releaseSingle      = "single"
+	releaseLive        = "live"
+	releaseCompilation = "compilation"
+)
+
+var (
+	bracketRegex = regexp.MustCompile(`\s*[(\[][^)\]]*[)\]]`)
+	editionRegex = regexp.MustCompile(`(?i)\b(remaster\w*|deluxe|expanded|anniversary|edition)\b`)
+)
+
+// releaseKind sorts a release in one of the filters offered to the user.
+func releaseKind(album types.Album) string {
+	switch strings.ToLower(album.ReleaseType) {
+	case "live":
+		return releaseLive
+	case "compilation":
+		return releaseCompilation
+	case "single", "ep", "epmini", "epsingle":
+		return releaseSingle
+	}
+
+	switch strings.ToLower(album.ProductType) {
+	case "single", "ep", "epmini":
+		return releaseSingle
+	}
+
+	return releaseAlbum
+}
+
+// editionKey is the same for the alternate editions of a release, e.g.
+// "Abbey Road" and "Abbey Road (Remastered 2019)". Only the parts naming an
+// edition are ignored, "Live (Disc 1)" and "Live (Disc 2)" stay apart. Qobuz
+// usually keeps that text in Version rather than in the title.
+func editionKey(album types.Album) string {
+	title := bracketRegex.ReplaceAllStringFunc(album.Title, func(part string) string {
+		if editionRegex.MatchString(part) {
+			return ""
+		}
+		return part
+	})
+
+	version, _ := album.Version.(string)
+	if editionRegex.MatchString(version) {
+		version = ""
+	}
+
+	return fmt.Sprintf("%d/%s/%s", album.Artist.Id, strings.ToLower(strings.TrimSpace(title)), strings.ToLower(strings.TrimSpace(version)))
+}
+
+// betterEdition reports whether a should be kept over b.
+func betterEdition(a, b types.Album) bool {
+	if a.MaximumBitDepth != b.MaximumBitDepth {
+		return a.MaximumBitDepth > b.MaximumBitDepth
+	}
+
+	if a.MaximumSamplingRate != b.MaximumSamplingRate {
+		return a.MaximumSamplingRate > b.MaximumSamplingRate
+	}
+
+	return a.TracksCount > b.TracksCount
+}
+
+// dedupeEditions keeps the best edition of every release, in the order the
+// releases were first seen.
+func dedupeEditions(albums []types.Album) []types.Album {
+	index := make(map[string]int)
+
+	var deduped []types.Album
+	for _, album := range albums {
+		key := editionKey(album)
+		if i, ok := index[key]; ok {
+			if betterEdition(album, deduped[i]) {
+				deduped[i] = album
+			}
+			continue
+		}
+
+		index[key] = len(deduped)
+		deduped = append(deduped, album)
+	}
+
+	return deduped
+}
+
+func downloadArtist(ctx context.Context, client *qobuz.QobuzClient, profile *Profile, quality types.Quality) {
+	var query string
+	if err := huh.NewInput().Title("Enter an artist name/url").Description("This is required to search for an artist.").Value(&query).Run(); err != nil {
+		return
+	}
+
+	regex := regexp.MustCompile(`https://open.qobuz.com/artist/(\d+)`)
+	matches := regex.FindStringSubmatch(query)
+
+	var artistId string
+	if len(matches) > 1 {
+		artistId = matches[1]
+	} else {
+		result := selectSearchResult(ctx, client, query, searchSelect[types.Artist]{
+			searchType:  qobuz.SearchArtists,
+			noun:        "artists",
+			title:       "Select an artist",
+			description: "Choose an artist to download.",
+			items:       func(r *qobuz.SearchResponse) []types.Artist { return r.Artists.Items },
+			label: func(artist types.Artist) string {
+				return fmt.Sprintf("%s (%d albums)", artist.Name, artist.AlbumsCount)
+			},
+		})
+		if result == nil {
+			return
+		}
+
+		artistId = fmt.Sprintf("%d", result.Id)
+	}
+
+	var (
+		artist *types.Artist
+		albums []types.Album
+		err    error
+	)
+	if err := spinner.New().Title("Fetching discography...").Action(func() {
+		artist, err = client.ArtistContext(ctx, artistId, nil)
+		if err != nil {
+			return
+		}
+
+		albums, err = client.ArtistAlbumsContext(ctx, artistId)
+	}).Run(); err != nil {
+		return
+	}
+
+	if err != nil {
+		fmt.Println("Failed to get artist discography:", err)
+		return
+	}
+
+	kinds := []string{releaseAlbum}
+	if err := huh.NewMultiSelect[string]().Title("Select release types").Description("Choose which releases of "+artist.Name+" to download.").Options(
+		huh.NewOption("Albums", releaseAlbum),
+		huh.NewOption("EPs and singles", releaseSingle),
+		huh.NewOption("Live albums", releaseLive),
+		huh.NewOption("Compilations", releaseCompilation),
+	).Value(&kinds).Run(); err != nil {
+		return
+	}
+
+	wanted := make(map[string]bool)
+	for _, kind := range kinds {
+		wanted[kind] = true
+	}
+
+	var selected []types.Album
+	for _, album := range albums {
+		if album.Streamable && wanted[releaseKind(album)] {
+			selected = append(selected, album)
+		}
+	}
+	selected = dedupeEditions(selected)
+
+	if len(selected) == 0 {
+		fmt.Println("No releases found.")
+		return
+	}
+
+	var confirm bool
+	if err := huh.NewConfirm().Title("Download discography").Description(fmt.Sprintf("Are you sure you want to download %d releases by %s ?", len(selected), artist.Name)).Value(&confirm).Run(); err != nil {
+		return
+	}
+
+	if !confirm {
+		return
+	}
+
+	failed := 0
+	for i, album := range selected {
+		fmt.Printf("\n[%d/%d] %s\n", i+1, len(selected), album.Title)
+
+		albumInfo, err := client.AlbumContext(ctx, album.Id)
+		if err == nil {
+			err = downloadAlbumTracks(ctx, client, profile, quality, albumInfo)
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			fmt.Println("Failed to download", album.Title+":", err)
+			failed++
+		}
+	}
+
+	fmt.Printf("\nDownloaded %d of %d releases by %s.\n", len(selected)-failed, len(selected), artist.Name)
+}
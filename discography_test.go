@@ -0,0 +1,88 @@
+package main
+
+import (
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"testing"
+)
+
+func testAlbum(id, title string, version interface{}) types.Album {
+	album := types.Album{Id: id, Title: title, Version: version}
+	album.Artist.Id = 1
+	return album
+}
+
+func TestReleaseKind(t *testing.T) {
+	for _, test := range []struct {
+		releaseType, productType string
+		want                     string
+	}{
+		{"album", "album", releaseAlbum},
+		{"", "", releaseAlbum},
+		{"live", "album", releaseLive},
+		{"Compilation", "album", releaseCompilation},
+		{"single", "", releaseSingle},
+		{"epmini", "", releaseSingle},
+		{"", "EP", releaseSingle},
+		{"", "single", releaseSingle},
+		{"album", "box-set", releaseAlbum},
+	} {
+		album := types.Album{ReleaseType: test.releaseType, ProductType: test.productType}
+		if got := releaseKind(album); got != test.want {
+			t.Errorf("releaseKind(%q, %q) = %q, want %q", test.releaseType, test.productType, got, test.want)
+		}
+	}
+}
+
+func TestEditionKey(t *testing.T) {
+	for _, test := range []struct {
+		a, b types.Album
+		same bool
+	}{
+		{testAlbum("1", "Abbey Road", nil), testAlbum("2", "Abbey Road (Remastered 2019)", nil), true},
+		{testAlbum("1", "Abbey Road", nil), testAlbum("2", "Abbey Road", "Remastered 2019"), true},
+		{testAlbum("1", "Rumours", nil), testAlbum("2", "RUMOURS [Super Deluxe]", nil), true},
+		{testAlbum("1", "Nevermind", nil), testAlbum("2", "Nevermind (30th Anniversary Edition)", nil), true},
+		{testAlbum("1", "Nevermind", nil), testAlbum("2", "Nevermind", "Expanded"), true},
+		{testAlbum("1", "Greatest Hits (Vol. 1)", nil), testAlbum("2", "Greatest Hits (Vol. 2)", nil), false},
+		{testAlbum("1", "Live (Disc 1)", nil), testAlbum("2", "Live (Disc 2)", nil), false},
+		{testAlbum("1", "Live", "Disc 1"), testAlbum("2", "Live", "Disc 2"), false},
+		{testAlbum("1", "Live (Disc 1)", nil), testAlbum("2", "Live (Disc 1) [Remastered]", nil), true},
+		{testAlbum("1", "Abbey Road", nil), types.Album{Id: "2", Title: "Abbey Road"}, false},
+	} {
+		if same := editionKey(test.a) == editionKey(test.b); same != test.same {
+			t.Errorf("%q and %q: same key = %v, want %v", editionKey(test.a), editionKey(test.b), same, test.same)
+		}
+	}
+}
+
+func TestDedupeEditions(t *testing.T) {
+	original := testAlbum("1", "Abbey Road", nil)
+	original.MaximumBitDepth = 16
+
+	remaster := testAlbum("2", "Abbey Road (Remastered 2019)", nil)
+	remaster.MaximumBitDepth = 24
+
+	deluxe := testAlbum("3", "Abbey Road", "Super Deluxe Edition")
+	deluxe.MaximumBitDepth = 24
+	deluxe.TracksCount = 40
+
+	volume1 := testAlbum("4", "Greatest Hits (Vol. 1)", nil)
+	volume2 := testAlbum("5", "Greatest Hits (Vol. 2)", nil)
+
+	hiRes := testAlbum("6", "Greatest Hits (Vol. 1) [Remastered]", nil)
+	hiRes.MaximumBitDepth = 24
+	hiRes.MaximumSamplingRate = 96
+
+	got := dedupeEditions([]types.Album{original, volume1, remaster, volume2, deluxe, hiRes})
+
+	want := []string{"3", "6", "5"}
+	if len(got) != len(want) {
+		t.Fatalf("kept %d releases, want %d", len(got), len(want))
+	}
+
+	for i, id := range want {
+		if got[i].Id != id {
+			t.Errorf("release %d = %s (%q), want %s", i, got[i].Id, got[i].Title, id)
+		}
+	}
+}
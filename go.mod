@@ -1,6 +1,6 @@
 module github.com/szerookii/goquobuz
 
-go 1.22.4
+go 1.24
 
 require (
 	github.com/charmbracelet/bubbles v0.20.0
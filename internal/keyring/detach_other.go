@@ -0,0 +1,7 @@
+//go:build !unix
+
+package keyring
+
+import "os/exec"
+
+func detach(cmd *exec.Cmd) {}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package keyring
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach runs cmd in a new session without a controlling terminal, so
+// prompts read stdin instead of /dev/tty.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,210 @@
+package keyring
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"hash"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const pbkdf2Iterations = 600000
+
+// deriveKey is replaced by the tests to count derivations.
+var deriveKey = pbkdf2.Key[hash.Hash]
+
+var ErrWrongPassphrase = errors.New("wrong keyring passphrase")
+
+// EncryptedFile keeps every secret in a single AES-256-GCM encrypted JSON
+// file, with a key derived from a passphrase by PBKDF2-SHA256. The key is
+// derived once and kept, with its salt, for the life of the EncryptedFile:
+// saves reuse the salt and only get a new nonce.
+type EncryptedFile struct {
+	Path       string
+	Passphrase func() (string, error)
+
+	mu         sync.Mutex
+	passphrase string
+	salt       []byte
+	key        []byte
+}
+
+type encryptedFile struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+func NewEncryptedFile(path string, passphrase func() (string, error)) *EncryptedFile {
+	return &EncryptedFile{Path: path, Passphrase: passphrase}
+}
+
+func (f *EncryptedFile) Get(service, account string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	secrets, err := f.load()
+	if err != nil {
+		return "", err
+	}
+
+	secret, ok := secrets[service+"/"+account]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return secret, nil
+}
+
+func (f *EncryptedFile) Set(service, account, secret string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	secrets[service+"/"+account] = secret
+	return f.save(secrets)
+}
+
+func (f *EncryptedFile) Delete(service, account string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := secrets[service+"/"+account]; !ok {
+		return nil
+	}
+
+	delete(secrets, service+"/"+account)
+	return f.save(secrets)
+}
+
+func (f *EncryptedFile) getPassphrase() (string, error) {
+	if f.passphrase != "" {
+		return f.passphrase, nil
+	}
+
+	if f.Passphrase == nil {
+		return "", errors.New("no keyring passphrase")
+	}
+
+	passphrase, err := f.Passphrase()
+	if err != nil {
+		return "", err
+	}
+
+	if passphrase == "" {
+		return "", errors.New("empty keyring passphrase")
+	}
+
+	f.passphrase = passphrase
+	return passphrase, nil
+}
+
+func (f *EncryptedFile) gcm(salt []byte) (cipher.AEAD, error) {
+	if f.key == nil || !bytes.Equal(f.salt, salt) {
+		passphrase, err := f.getPassphrase()
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := deriveKey(sha256.New, passphrase, salt, pbkdf2Iterations, 32)
+		if err != nil {
+			return nil, err
+		}
+
+		f.salt, f.key = salt, key
+	}
+
+	block, err := aes.NewCipher(f.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func (f *EncryptedFile) load() (map[string]string, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]string), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	gcm, err := f.gcm(file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		f.passphrase, f.salt, f.key = "", nil, nil
+		return nil, ErrWrongPassphrase
+	}
+
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+func (f *EncryptedFile) save(secrets map[string]string) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	file := encryptedFile{
+		Salt: f.salt,
+	}
+	if file.Salt == nil {
+		file.Salt = make([]byte, 16)
+		if _, err := rand.Read(file.Salt); err != nil {
+			return err
+		}
+	}
+
+	gcm, err := f.gcm(file.Salt)
+	if err != nil {
+		return err
+	}
+
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Data = gcm.Seal(nil, file.Nonce, plaintext, nil)
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(f.Path, data, 0600)
+}
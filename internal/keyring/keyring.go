@@ -0,0 +1,24 @@
+// Package keyring stores small secrets such as passwords and tokens, in the
+// operating system keyring when there is one and in a passphrase encrypted
+// file otherwise.
+package keyring
+
+import "errors"
+
+var ErrNotFound = errors.New("secret not found in keyring")
+
+type Store interface {
+	Get(service, account string) (string, error)
+	Set(service, account, secret string) error
+	Delete(service, account string) error
+}
+
+// Default returns the system keyring when it answers a lookup and an
+// encrypted file at path otherwise. passphrase is only called if the file is used.
+func Default(path string, passphrase func() (string, error)) Store {
+	if system := System(); system != nil {
+		return system
+	}
+
+	return NewEncryptedFile(path, passphrase)
+}
@@ -0,0 +1,150 @@
+package keyring
+
+import (
+	"bytes"
+	"crypto/pbkdf2"
+	"errors"
+	"hash"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+
+	if _, err := store.Get("goqobuz", "default/token"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get on an empty store = %v, want ErrNotFound", err)
+	}
+
+	if err := store.Set("goqobuz", "default/token", "secret-token"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Set("goqobuz", "studio/token", "other-token"); err != nil {
+		t.Fatal(err)
+	}
+
+	if secret, err := store.Get("goqobuz", "default/token"); err != nil || secret != "secret-token" {
+		t.Fatalf("Get = %q, %v; want secret-token", secret, err)
+	}
+
+	if err := store.Delete("goqobuz", "default/token"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get("goqobuz", "default/token"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete = %v, want ErrNotFound", err)
+	}
+
+	if err := store.Delete("goqobuz", "default/token"); err != nil {
+		t.Fatalf("deleting a missing secret: %v", err)
+	}
+
+	if secret, err := store.Get("goqobuz", "studio/token"); err != nil || secret != "other-token" {
+		t.Fatalf("Get = %q, %v; want other-token", secret, err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	testStore(t, NewMemory())
+}
+
+func passphrase(value string) func() (string, error) {
+	return func() (string, error) { return value, nil }
+}
+
+func TestEncryptedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	testStore(t, NewEncryptedFile(path, passphrase("correct horse")))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(data, []byte("other-token")) {
+		t.Error("the secret is stored in clear")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("file mode = %o, want 600", perm)
+	}
+
+	// A new instance, e.g. the next run, reads what the first one wrote.
+	if secret, err := NewEncryptedFile(path, passphrase("correct horse")).Get("goqobuz", "studio/token"); err != nil || secret != "other-token" {
+		t.Errorf("Get = %q, %v; want other-token", secret, err)
+	}
+
+	if _, err := NewEncryptedFile(path, passphrase("wrong")).Get("goqobuz", "studio/token"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Get with a wrong passphrase = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestEncryptedFileDerivesKeyOnce(t *testing.T) {
+	derivations := 0
+	t.Cleanup(func() { deriveKey = pbkdf2.Key[hash.Hash] })
+	deriveKey = func(h func() hash.Hash, password string, salt []byte, iter, keyLength int) ([]byte, error) {
+		derivations++
+		return pbkdf2.Key(h, password, salt, iter, keyLength)
+	}
+
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	store := NewEncryptedFile(path, passphrase("correct horse"))
+
+	// What a password login does: a few lookups and saves.
+	for _, key := range []string{"password_hash", "token", "session"} {
+		if _, err := store.Get("goqobuz", "default/"+key); !errors.Is(err, ErrNotFound) {
+			t.Fatal(err)
+		}
+
+		if err := store.Set("goqobuz", "default/"+key, "value"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if derivations != 1 {
+		t.Errorf("derived the key %d times, want once", derivations)
+	}
+
+	// Another instance, e.g. another process, derives it once as well.
+	other := NewEncryptedFile(path, passphrase("correct horse"))
+	for i := 0; i < 3; i++ {
+		if _, err := other.Get("goqobuz", "default/token"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if derivations != 2 {
+		t.Errorf("derived the key %d times, want twice", derivations)
+	}
+
+	// A wrong passphrase must not stay cached.
+	wrong := NewEncryptedFile(path, passphrase("wrong"))
+	for i := 0; i < 2; i++ {
+		if _, err := wrong.Get("goqobuz", "default/token"); !errors.Is(err, ErrWrongPassphrase) {
+			t.Fatalf("Get with a wrong passphrase = %v, want ErrWrongPassphrase", err)
+		}
+	}
+}
+
+type brokenStore struct{ Store }
+
+func (brokenStore) Get(service, account string) (string, error) {
+	return "", errors.New("secret-tool: exit status 1: The name org.freedesktop.secrets was not provided by any .service files")
+}
+
+func TestUsable(t *testing.T) {
+	if !usable(NewMemory()) {
+		t.Error("a keyring answering ErrNotFound is not usable")
+	}
+
+	if usable(brokenStore{}) {
+		t.Error("a keyring failing every lookup is usable")
+	}
+}
@@ -0,0 +1,41 @@
+package keyring
+
+import "sync"
+
+// Memory is an in-memory Store, for tests and headless machines.
+type Memory struct {
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+func NewMemory() *Memory {
+	return &Memory{secrets: make(map[string]string)}
+}
+
+func (m *Memory) Get(service, account string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	secret, ok := m.secrets[service+"/"+account]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return secret, nil
+}
+
+func (m *Memory) Set(service, account, secret string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.secrets[service+"/"+account] = secret
+	return nil
+}
+
+func (m *Memory) Delete(service, account string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.secrets, service+"/"+account)
+	return nil
+}
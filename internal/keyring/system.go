@@ -0,0 +1,122 @@
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// system talks to the Secret Service through secret-tool on Linux and to the
+// login keychain through security on macOS.
+type system struct {
+	tool string
+}
+
+// System returns the keyring of the operating system, or nil when none is
+// reachable (e.g. no D-Bus session on a headless Linux box, or a session
+// without a Secret Service daemon).
+func System() Store {
+	var tool string
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+			return nil
+		}
+		tool = "secret-tool"
+	case "darwin":
+		tool = "security"
+	default:
+		return nil
+	}
+
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return nil
+	}
+
+	s := &system{tool: path}
+	if !usable(s) {
+		return nil
+	}
+
+	return s
+}
+
+// usable looks up a secret that does not exist: a keyring that answers
+// ErrNotFound works, any other error means it cannot be reached.
+func usable(store Store) bool {
+	_, err := store.Get("keyring-probe", "probe")
+	return err == nil || errors.Is(err, ErrNotFound)
+}
+
+func (s *system) run(stdin string, args ...string) (string, error) {
+	cmd := exec.Command(s.tool, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	detach(cmd)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() == 0 {
+			// Both tools exit silently with a non-zero code on a missing item.
+			return "", ErrNotFound
+		}
+
+		return "", fmt.Errorf("%s: %v: %s", s.tool, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSuffix(stdout.String(), "\n"), nil
+}
+
+func (s *system) Get(service, account string) (string, error) {
+	if runtime.GOOS == "darwin" {
+		secret, err := s.run("", "find-generic-password", "-s", service, "-a", account, "-w")
+		if err != nil && strings.Contains(err.Error(), "could not be found") {
+			return "", ErrNotFound
+		}
+		return secret, err
+	}
+
+	secret, err := s.run("", "lookup", "service", service, "account", account)
+	if err == nil && secret == "" {
+		return "", ErrNotFound
+	}
+
+	return secret, err
+}
+
+func (s *system) Set(service, account, secret string) error {
+	if runtime.GOOS == "darwin" {
+		// With -w last and no value, security prompts for the password and
+		// its confirmation instead of taking it from the command line, where
+		// ps would show it. Detached from the terminal, it reads them from
+		// stdin.
+		_, err := s.run(secret+"\n"+secret+"\n", "add-generic-password", "-U", "-s", service, "-a", account, "-w")
+		return err
+	}
+
+	_, err := s.run(secret, "store", "--label="+service+" "+account, "service", service, "account", account)
+	return err
+}
+
+func (s *system) Delete(service, account string) error {
+	var err error
+	if runtime.GOOS == "darwin" {
+		_, err = s.run("", "delete-generic-password", "-s", service, "-a", account)
+	} else {
+		_, err = s.run("", "clear", "service", service, "account", account)
+	}
+
+	if errors.Is(err, ErrNotFound) || (err != nil && strings.Contains(err.Error(), "could not be found")) {
+		return nil
+	}
+
+	return err
+}
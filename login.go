@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/huh"
+	"github.com/szerookii/goquobuz/internal/keyring"
+	"github.com/szerookii/goquobuz/qobuz"
+)
+
+// login reuses the saved session or token of the profile when there is one
+// and only asks for the password when both are missing or rejected. Secrets
+// only ever live in the keyring, except the ones given as overrides in
+// effective and profile, which are never saved.
+func login(ctx context.Context, config, effective *Config, name string, profile *Profile, secrets keyring.Store) (*qobuz.QobuzClient, error) {
+	sessions := &keyringSessionStore{secrets: secrets, profile: name}
+
+	passwordHash := profile.PasswordHash
+	if passwordHash == "" && profile.Password != "" {
+		passwordHash = qobuz.HashPassword(profile.Password)
+	}
+	overridden := passwordHash != ""
+
+	if passwordHash == "" {
+		var err error
+		passwordHash, err = getSecret(secrets, name, "password_hash")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Token clients log in again on their own when the token expires, and
+	// save the new one in the session store.
+	opts := append(clientOptions(effective), qobuz.WithSessionStore(sessions))
+
+	if profile.Token != "" {
+		return qobuz.NewFromAuthTokenContext(ctx, profile.Token, opts...)
+	}
+	if profile.Email != "" && passwordHash != "" {
+		opts = append(opts, qobuz.WithLoginCredentials(profile.Email, passwordHash))
+	}
+
+	session, err := sessions.Load()
+	if err != nil {
+		fmt.Println("Failed to read session, logging in again:", err)
+	}
+
+	if session != nil && session.UserAuthToken != "" {
+		client, err := qobuz.NewFromAuthTokenContext(ctx, session.UserAuthToken, opts...)
+		if err == nil {
+			return client, nil
+		}
+
+		if !errors.Is(err, qobuz.ErrUnauthorized) {
+			return nil, err
+		}
+
+		fmt.Println("Saved session expired, logging in again...")
+		if err := sessions.Clear(); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := getSecret(secrets, name, "token")
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		client, err := qobuz.NewFromAuthTokenContext(ctx, token, opts...)
+		if err == nil {
+			return client, sessions.Save(client.Session())
+		}
+
+		if !errors.Is(err, qobuz.ErrUnauthorized) {
+			return nil, err
+		}
+
+		fmt.Println("Saved token rejected, logging in with a password...")
+	}
+
+	if profile.Email == "" {
+		if err := huh.NewInput().Title("Enter your Qobuz email").Description("This is required to login to Qobuz.").Value(&profile.Email).Run(); err != nil {
+			return nil, err
+		}
+
+		config.Profiles[name].Email = profile.Email
+	}
+
+	if passwordHash == "" {
+		var password string
+		if err := huh.NewInput().Title("Enter your Qobuz password").Description("This is required to login to Qobuz.").EchoMode(huh.EchoModePassword).Value(&password).Run(); err != nil {
+			return nil, err
+		}
+
+		passwordHash = qobuz.HashPassword(password)
+	}
+
+	client, err := qobuz.NewFromPasswordHashContext(ctx, profile.Email, passwordHash, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sessions.Save(client.Session()); err != nil {
+		return nil, fmt.Errorf("failed to save session: %v", err)
+	}
+
+	if !overridden {
+		if err := secrets.Set(keyringService, name+"/password_hash", passwordHash); err != nil {
+			return nil, fmt.Errorf("failed to save password: %v", err)
+		}
+	}
+
+	// The email may have just been entered.
+	if err := writeConfig(configPath, config); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
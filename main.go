@@ -1,23 +1,23 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
-	"github.com/charmbracelet/huh/spinner"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/szerookii/goquobuz/qobuz"
 	"github.com/szerookii/goquobuz/qobuz/types"
 	"io"
-	"log"
-	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -29,6 +29,7 @@ type progressWriter struct {
 	file       *os.File
 	reader     io.Reader
 	onProgress func(float64)
+	done       chan error
 }
 
 func (pw *progressWriter) Start() {
@@ -36,6 +37,7 @@ func (pw *progressWriter) Start() {
 	if err != nil {
 		p.Send(errors.New("failed to download track"))
 	}
+	pw.done <- err
 }
 
 func (pw *progressWriter) Write(p []byte) (int, error) {
@@ -46,17 +48,6 @@ func (pw *progressWriter) Write(p []byte) (int, error) {
 	return len(p), nil
 }
 
-func getResponse(url string) (*http.Response, error) {
-	resp, err := http.Get(url) // nolint:gosec
-	if err != nil {
-		log.Fatal(err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("receiving status of %d for url: %s", resp.StatusCode, url)
-	}
-	return resp, nil
-}
-
 var helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262")).Render
 
 const (
@@ -78,6 +69,7 @@ type model struct {
 	pw       *progressWriter
 	progress progress.Model
 	err      error
+	cancel   context.CancelFunc
 }
 
 func (m model) Init() tea.Cmd {
@@ -87,6 +79,7 @@ func (m model) Init() tea.Cmd {
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.cancel()
 		return m, tea.Quit
 
 	case tea.WindowSizeMsg:
@@ -135,115 +128,172 @@ func (m model) View() string {
 		pad + helpStyle("Press any key to quit")
 }
 
-type Config struct {
-	Email          string
-	Password       string
-	DownloadFolder string
-}
+func main() {
+	fmt.Println("   ▄████████  ▄█     ▄███████▄ ▀█████████▄  ███    █▄   ▄███████▄  ")
+	fmt.Println("  ███    ███ ███    ███    ███   ███    ███ ███    ███ ██▀     ▄██ ")
+	fmt.Println("  ███    ███ ███▌   ███    ███   ███    ███ ███    ███       ▄███▀ ")
+	fmt.Println(" ▄███▄▄▄▄██▀ ███▌   ███    ███  ▄███▄▄▄██▀  ███    ███  ▀█▀▄███▀▄▄ ")
+	fmt.Println("▀▀███▀▀▀▀▀   ███▌ ▀█████████▀  ▀▀███▀▀▀██▄  ███    ███   ▄███▀   ▀ ")
+	fmt.Println("▀███████████ ███    ███          ███    ██▄ ███    ███ ▄███▀       ")
+	fmt.Println("  ███    ███ ███    ███          ███    ███ ███    ███ ███▄     ▄█ ")
+	fmt.Println("  ███    ███ █▀    ▄████▀      ▄█████████▀  ████████▀   ▀████████▀ ")
+	fmt.Println("  ███    ███                                                       ")
+	fmt.Println("")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-func readConfig() (*Config, error) {
-	var config *Config
+	profileName := flag.String("profile", os.Getenv("GOQOBUZ_PROFILE"), "name of the profile to use (env GOQOBUZ_PROFILE)")
+	configOverride := flag.String("config", os.Getenv("GOQOBUZ_CONFIG"), "path of the config file (env GOQOBUZ_CONFIG)")
+	flagValues := registerSettingFlags(flag.CommandLine)
+	flag.Parse()
 
-	if _, err := os.Stat("config.json"); os.IsNotExist(err) {
-		fmt.Println("Config file does not exist, creating a new one...")
+	if arg, ok := trailingFlag(flag.Args()); ok {
+		fmt.Printf("Flags must come before the command, move %s before %s.\n", arg, flag.Arg(0))
+		os.Exit(2)
+	}
 
-		config = new(Config)
-		config.DownloadFolder = "downloads"
+	if err := initPaths(*configOverride); err != nil {
+		panic(err)
+	}
 
-		if err := writeConfig("config.json", config); err != nil {
-			return nil, fmt.Errorf("failed to create empty config file: %v", err)
+	// config validate only reads: it neither migrates, creates nor upgrades
+	// the config file.
+	validating := flag.Arg(0) == "config" && flag.Arg(1) == "validate"
+
+	if *configOverride == "" && !validating {
+		migrated, err := migrateLegacyFiles()
+		if err != nil {
+			fmt.Println("Failed to migrate ./config.json:", err)
+			os.Exit(1)
 		}
 
-		return config, nil
+		if migrated {
+			fmt.Println("Migrated ./config.json to", configPath+".")
+		}
 	}
 
-	data, err := os.ReadFile("config.json")
+	var (
+		config   *Config
+		upgraded bool
+		err      error
+	)
+	if validating {
+		config, upgraded, err = loadConfig()
+	} else {
+		config, err = readConfig()
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %v", err)
+		fmt.Println("Invalid config", configPath+":")
+		printConfigErrors(err)
+		os.Exit(1)
 	}
 
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %v", err)
+	if flag.Arg(0) == "profile" {
+		if err := runProfileCommand(config, flag.Args()[1:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	return config, nil
-}
-
-func writeConfig(configPath string, config *Config) error {
-	data, err := json.MarshalIndent(config, "", "  ")
+	name, storedProfile, err := config.profile(*profileName)
 	if err != nil {
-		return errors.New("failed to marshal config to JSON")
+		fmt.Println(err)
+		os.Exit(1)
 	}
 
-	if err := os.WriteFile("config.json", data, 0644); err != nil {
-		return fmt.Errorf("failed to write config file: %v", err)
+	effective, profile, sources, err := effectiveConfig(config, storedProfile, flag.CommandLine, flagValues)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 
-	return nil
-}
+	if flag.Arg(0) == "config" && flag.Arg(1) == "show" {
+		showConfig(name, effective, profile, sources)
+		return
+	}
 
-func main() {
-	fmt.Println("   ▄████████  ▄█     ▄███████▄ ▀█████████▄  ███    █▄   ▄███████▄  ")
-	fmt.Println("  ███    ███ ███    ███    ███   ███    ███ ███    ███ ██▀     ▄██ ")
-	fmt.Println("  ███    ███ ███▌   ███    ███   ███    ███ ███    ███       ▄███▀ ")
-	fmt.Println(" ▄███▄▄▄▄██▀ ███▌   ███    ███  ▄███▄▄▄██▀  ███    ███  ▀█▀▄███▀▄▄ ")
-	fmt.Println("▀▀███▀▀▀▀▀   ███▌ ▀█████████▀  ▀▀███▀▀▀██▄  ███    ███   ▄███▀   ▀ ")
-	fmt.Println("▀███████████ ███    ███          ███    ██▄ ███    ███ ▄███▀       ")
-	fmt.Println("  ███    ███ ███    ███          ███    ███ ███    ███ ███▄     ▄█ ")
-	fmt.Println("  ███    ███ █▀    ▄████▀      ▄█████████▀  ████████▀   ▀████████▀ ")
-	fmt.Println("  ███    ███                                                       ")
-	fmt.Println("")
-	config, err := readConfig()
-	if err != nil {
-		panic(err)
+	// Overrides skip readConfig's validation, check them like the file.
+	if errs := validateProfile("Profiles."+name, profile); len(errs) > 0 {
+		fmt.Println("Invalid settings for profile", name+":")
+		printConfigErrors(errors.Join(errs...))
+		os.Exit(1)
 	}
 
-	//login:
-	if config.Email == "" || config.Password == "" {
-		var email, password string
-		if err := huh.NewInput().Title("Enter your Qobuz email").Description("This is required to login to Qobuz.").Value(&email).Run(); err != nil {
-			return
+	if validating {
+		fmt.Println(configPath, "is valid.")
+		if upgraded {
+			fmt.Printf("It will be upgraded to version %d on the next run.\n", configVersion)
 		}
+		return
+	}
 
-		if err := huh.NewInput().Title("Enter your Qobuz password").Description("This is required to login to Qobuz.").EchoMode(huh.EchoModePassword).Value(&password).Run(); err != nil {
-			return
-		}
+	fmt.Println("Using profile", name+".")
 
-		config.Email = email
-		config.Password = password
+	os.MkdirAll(profile.DownloadFolder, 0755)
 
-		if err := writeConfig("config.json", config); err != nil {
-			panic(err)
-		}
+	secrets := openKeyring()
+	if err := migrateSecrets(config, secrets); err != nil {
+		fmt.Println("Failed to move credentials to the keyring:", err)
+		os.Exit(1)
 	}
 
-	os.Mkdir(config.DownloadFolder, 0755)
-
-	client, err := qobuz.NewFromCredentials(config.Email, config.Password)
-	if err != nil {
+	client, err := login(ctx, config, effective, name, profile, secrets)
+	if errors.Is(err, qobuz.ErrUnauthorized) {
+		fmt.Println("Failed to login: invalid email or password.")
+		return
+	} else if err != nil {
 		fmt.Println("Failed to create Qobuz client:", err)
 		return
 	}
 
+	quality := client.MaxAllowedQuality()
+	if quality == 0 {
+		fmt.Println("Your Qobuz account cannot stream any format.")
+		return
+	}
+
+	if profile.Quality != 0 {
+		if client.CanStream(profile.Quality) {
+			quality = profile.Quality
+		} else {
+			fmt.Println("Your subscription does not allow", profile.Quality.String()+".")
+		}
+	}
+
+	if status := client.SubscriptionStatus(); !status.Active {
+		fmt.Println("Warning: your Qobuz subscription does not look active.")
+	}
+
+	fmt.Println("Downloading in", quality.String()+".")
+
 	var mode int
 	if err := huh.NewSelect[int]().Title("Select a mode").Description("Choose a mode to continue.").Options(
 		huh.NewOption("Download track", 1),
 		huh.NewOption("Download album", 2),
+		huh.NewOption("Download artist discography", 3),
+		huh.NewOption("Download playlist", 4),
 	).Value(&mode).Run(); err != nil {
 		return
 	}
 
 	switch mode {
 	case 1:
-		downloadTrack(client, config)
+		downloadTrack(ctx, client, profile, quality)
 		break
 	case 2:
-		downloadAlbum(client, config)
+		downloadAlbum(ctx, client, profile, quality)
+		break
+	case 3:
+		downloadArtist(ctx, client, profile, quality)
+		break
+	case 4:
+		downloadPlaylist(ctx, client, profile, quality)
 		break
 	}
 }
 
-func downloadAlbum(client *qobuz.QobuzClient, config *Config) {
+func downloadAlbum(ctx context.Context, client *qobuz.QobuzClient, profile *Profile, quality types.Quality) {
 	var query string
 	if err := huh.NewInput().Title("Enter an album name/url").Description("This is required to search for an album.").Value(&query).Run(); err != nil {
 		return
@@ -258,37 +308,19 @@ func downloadAlbum(client *qobuz.QobuzClient, config *Config) {
 
 		fmt.Println("Album ID in link:", albumId)
 	} else {
-		var albums []types.Album
-		if err := spinner.New().Title("Searching for albums...").Action(func() {
-			response, err := client.Search(query)
-			if err != nil {
-				fmt.Println("Failed to search for albums:", err)
-				return
-			}
-
-			albums = response.Albums.Items
-		}).Run(); err != nil {
-			return
-		}
-
-		if len(albums) == 0 {
-			fmt.Println("No albums found.")
-			return
-		}
-
-		var options []huh.Option[int]
-		for i, album := range albums {
-			options = append(options, huh.NewOption[int](album.Title+" by "+album.Artist.Name, i))
-		}
-
-		var selectedAlbum int
-		if err := huh.NewSelect[int]().Title("Select an album").Description("Choose an album to download.").Options(options...).Value(&selectedAlbum).Run(); err != nil {
+		album = selectSearchResult(ctx, client, query, searchSelect[types.Album]{
+			searchType:  qobuz.SearchAlbums,
+			noun:        "albums",
+			title:       "Select an album",
+			description: "Choose an album to download.",
+			items:       func(r *qobuz.SearchResponse) []types.Album { return r.Albums.Items },
+			label:       func(album types.Album) string { return album.Title + " by " + album.Artist.Name },
+		})
+		if album == nil {
 			return
 		}
 
-		album = &albums[selectedAlbum]
-
-		albumInfo, err := client.Album(album.Id)
+		albumInfo, err := client.AlbumContext(ctx, album.Id)
 		if err != nil {
 			fmt.Println("Failed to get album info:", err)
 			return
@@ -303,38 +335,56 @@ func downloadAlbum(client *qobuz.QobuzClient, config *Config) {
 			return
 		}
 
-		os.Mkdir(filepath.Join(config.DownloadFolder, albumInfo.Title), 0755)
+		if err := downloadAlbumTracks(ctx, client, profile, quality, albumInfo); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
 
-		for _, track := range albumInfo.Tracks.Items {
-		searchurl:
-			trackURL, err := client.DownloadFileLink(fmt.Sprintf("%d", track.Id), 27)
-			if err != nil {
-				fmt.Println("Failed to get download link:", err)
-				return
-			}
+// downloadAlbumTracks downloads every track of the album into its own folder.
+func downloadAlbumTracks(ctx context.Context, client *qobuz.QobuzClient, profile *Profile, quality types.Quality, albumInfo *types.FullAlbum) error {
+	os.Mkdir(filepath.Join(profile.DownloadFolder, albumInfo.Title), 0755)
 
-			if trackURL == nil || trackURL.Url == "" {
-				time.Sleep(1 * time.Second)
-				goto searchurl
-			}
+	downloadAt := func(i int, showProgress bool) error {
+		track := albumInfo.Tracks.Items[i]
 
-			filePath := filepath.Join(config.DownloadFolder, albumInfo.Title, fmt.Sprintf("%d - %s.%s", track.TrackNumber, track.Title, strings.Split(trackURL.MimeType, "/")[1]))
+		trackURL, err := client.DownloadFileLinkContext(ctx, fmt.Sprintf("%d", track.Id), int(quality))
+		if err != nil {
+			return fmt.Errorf("failed to get download link: %v", err)
+		}
 
-			fmt.Println("Downloading", track.Title+"...")
+		filePath := albumTrackPath(profile, trackNaming{
+			Title:       track.Title,
+			Artist:      track.Performer.Name,
+			Album:       albumInfo.Title,
+			TrackNumber: track.TrackNumber,
+			DiscNumber:  track.MediaNumber,
+		}, strings.Split(trackURL.MimeType, "/")[1])
 
-			if err := downloadProgress(trackURL.Url, filePath); err != nil {
-				fmt.Println("Failed to download track:", err)
-				return
-			}
+		fmt.Println("Downloading", track.Title+"...")
+
+		download := downloadFile
+		if showProgress {
+			download = downloadProgress
+		}
 
-			fmt.Printf("Downloaded %s (%d Bit / %.2f kHz).\n", track.Title, trackURL.BitDepth, trackURL.SamplingRate)
+		if err := download(ctx, client, trackURL.Url, filePath); err != nil {
+			return fmt.Errorf("failed to download track: %v", err)
 		}
 
-		fmt.Printf("\nDownloaded %s.\n", albumInfo.Title)
+		fmt.Printf("Downloaded %s (%d Bit / %.2f kHz).\n", track.Title, trackURL.BitDepth, trackURL.SamplingRate)
+		return nil
+	}
+
+	if err := forEachConcurrently(len(albumInfo.Tracks.Items), profile.Concurrency, downloadAt); err != nil {
+		return err
 	}
+
+	fmt.Printf("\nDownloaded %s.\n", albumInfo.Title)
+	return nil
 }
 
-func downloadTrack(client *qobuz.QobuzClient, config *Config) {
+func downloadTrack(ctx context.Context, client *qobuz.QobuzClient, profile *Profile, quality types.Quality) {
 	var query string
 	if err := huh.NewInput().Title("Enter a track name/url").Description("This is required to search for a track.").Value(&query).Run(); err != nil {
 		return
@@ -349,35 +399,17 @@ func downloadTrack(client *qobuz.QobuzClient, config *Config) {
 
 		fmt.Println("Track ID in link:", trackId)
 	} else {
-		var tracks []types.Track
-		if err := spinner.New().Title("Searching for tracks...").Action(func() {
-			response, err := client.Search(query)
-			if err != nil {
-				fmt.Println("Failed to search for tracks:", err)
-				return
-			}
-
-			tracks = response.Tracks.Items
-		}).Run(); err != nil {
+		track = selectSearchResult(ctx, client, query, searchSelect[types.Track]{
+			searchType:  qobuz.SearchTracks,
+			noun:        "tracks",
+			title:       "Select a track",
+			description: "Choose a track to download.",
+			items:       func(r *qobuz.SearchResponse) []types.Track { return r.Tracks.Items },
+			label:       func(track types.Track) string { return track.Title + " by " + track.Performer.Name },
+		})
+		if track == nil {
 			return
 		}
-
-		if len(tracks) == 0 {
-			fmt.Println("No tracks found.")
-			return
-		}
-
-		var options []huh.Option[int]
-		for i, track := range tracks {
-			options = append(options, huh.NewOption[int](track.Title+" by "+track.Performer.Name, i))
-		}
-
-		var selectedTrack int
-		if err := huh.NewSelect[int]().Title("Select a track").Description("Choose a track to download.").Options(options...).Value(&selectedTrack).Run(); err != nil {
-			return
-		}
-
-		track = &tracks[selectedTrack]
 	}
 
 	if track == nil {
@@ -394,68 +426,153 @@ func downloadTrack(client *qobuz.QobuzClient, config *Config) {
 		return
 	}
 
-searchurl:
-	trackURL, err := client.DownloadFileLink(fmt.Sprintf("%d", track.Id), 27)
+	trackURL, err := client.DownloadFileLinkContext(ctx, fmt.Sprintf("%d", track.Id), int(quality))
 	if err != nil {
 		fmt.Println("Failed to get download link:", err)
 		return
 	}
 
-	if trackURL == nil || trackURL.Url == "" {
-		time.Sleep(1 * time.Second)
-		goto searchurl
-	}
-
-	filename := filepath.Base(track.Title + " - " + track.Performer.Name + "." + strings.Split(trackURL.MimeType, "/")[1])
+	filename := trackFilename(profile.NamingTemplate, "{title} - {artist}", trackNaming{
+		Title:       track.Title,
+		Artist:      track.Performer.Name,
+		Album:       track.Album.Title,
+		TrackNumber: track.TrackNumber,
+		DiscNumber:  track.MediaNumber,
+	}, strings.Split(trackURL.MimeType, "/")[1])
 
-	if err := downloadProgress(trackURL.Url, filepath.Join(config.DownloadFolder, filename)); err != nil {
+	if err := downloadProgress(ctx, client, trackURL.Url, filepath.Join(profile.DownloadFolder, filename)); err != nil {
 		fmt.Println("Failed to download track:", err)
 		return
 	}
 
-	fmt.Printf("Downloaded %s to %s (%d Bit / %.2f kHz)\n", filename, config.DownloadFolder, trackURL.BitDepth, trackURL.SamplingRate)
+	fmt.Printf("Downloaded %s to %s (%d Bit / %.2f kHz)\n", filename, profile.DownloadFolder, trackURL.BitDepth, trackURL.SamplingRate)
 }
 
-func downloadProgress(url, outputPath string) error {
-	resp, err := getResponse(url)
-	if err != nil {
-		return err
+// forEachConcurrently calls fn for 0..n-1 with at most concurrency calls in
+// flight and returns the first error. The progress bar can only show one
+// download, so it is only enabled without concurrency.
+func forEachConcurrently(n, concurrency int, fn func(i int, showProgress bool) error) error {
+	if concurrency <= 1 {
+		for i := 0; i < n; i++ {
+			if err := fn(i, true); err != nil {
+				return err
+			}
+		}
+
+		return nil
 	}
 
-	defer resp.Body.Close() // nolint:errcheck
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
 
-	if resp.ContentLength <= 0 {
-		return errors.New("could not get content length")
+	sem := make(chan struct{}, concurrency)
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(i, false); err != nil {
+				once.Do(func() { firstErr = err })
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+// writePart creates outputPath through write, which fills a ".part" file
+// renamed once it returned without error. An interrupted download never
+// leaves a file at outputPath that would pass for a complete track.
+func writePart(outputPath string, write func(file *os.File) error) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
 	}
 
-	file, err := os.Create(outputPath)
+	partPath := outputPath + ".part"
+
+	file, err := os.Create(partPath)
 	if err != nil {
 		return err
 	}
 
-	defer file.Close()
+	err = write(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
 
-	pw := &progressWriter{
-		total:  int(resp.ContentLength),
-		file:   file,
-		reader: resp.Body,
-		onProgress: func(ratio float64) {
-			p.Send(progressMsg(ratio))
-		},
+	if err != nil {
+		os.Remove(partPath)
+		return err
 	}
 
-	m := model{
-		pw:       pw,
-		progress: progress.New(progress.WithDefaultGradient()),
+	return os.Rename(partPath, outputPath)
+}
+
+// downloadFile is downloadProgress without the progress bar, safe to call
+// from several goroutines.
+func downloadFile(ctx context.Context, client *qobuz.QobuzClient, url, outputPath string) error {
+	resp, err := client.StreamContext(ctx, url)
+	if err != nil {
+		return err
 	}
 
-	p = tea.NewProgram(m)
+	defer resp.Body.Close() // nolint:errcheck
+
+	return writePart(outputPath, func(file *os.File) error {
+		_, err := io.Copy(file, resp.Body)
+		return err
+	})
+}
 
-	go pw.Start()
+func downloadProgress(ctx context.Context, client *qobuz.QobuzClient, url, outputPath string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	if _, err := p.Run(); err != nil {
+	resp, err := client.StreamContext(ctx, url)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	defer resp.Body.Close() // nolint:errcheck
+
+	if resp.ContentLength <= 0 {
+		return errors.New("could not get content length")
+	}
+
+	return writePart(outputPath, func(file *os.File) error {
+		pw := &progressWriter{
+			total:  int(resp.ContentLength),
+			file:   file,
+			reader: resp.Body,
+			done:   make(chan error, 1),
+			onProgress: func(ratio float64) {
+				p.Send(progressMsg(ratio))
+			},
+		}
+
+		m := model{
+			pw:       pw,
+			progress: progress.New(progress.WithDefaultGradient()),
+			cancel:   cancel,
+		}
+
+		p = tea.NewProgram(m, tea.WithContext(ctx))
+
+		go pw.Start()
+
+		if _, err := p.Run(); err != nil {
+			cancel()
+			<-pw.done
+			return err
+		}
+
+		return <-pw.done
+	})
 }
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type trackNaming struct {
+	Title       string
+	Artist      string
+	Album       string
+	TrackNumber int
+	DiscNumber  int
+}
+
+var filenameReplacer = strings.NewReplacer("/", "-", "\\", "-")
+
+// trackFilename fills template with the track fields, or fallback when the
+// profile has no template. The extension is appended.
+func trackFilename(template, fallback string, track trackNaming, extension string) string {
+	if template == "" {
+		template = fallback
+	}
+
+	name := strings.NewReplacer(
+		"{title}", filenameReplacer.Replace(track.Title),
+		"{artist}", filenameReplacer.Replace(track.Artist),
+		"{album}", filenameReplacer.Replace(track.Album),
+		"{track_number}", strconv.Itoa(track.TrackNumber),
+		"{disc_number}", strconv.Itoa(track.DiscNumber),
+	).Replace(template)
+
+	return name + "." + extension
+}
+
+// albumTrackPath is where album downloads put a track: one folder per album.
+func albumTrackPath(profile *Profile, track trackNaming, extension string) string {
+	return filepath.Join(profile.DownloadFolder, track.Album, trackFilename(profile.NamingTemplate, "{track_number} - {title}", track, extension))
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const appName = "goqobuz"
+
+// Set by initPaths. Config goes in $XDG_CONFIG_HOME/goqobuz, sessions and
+// credentials in $XDG_STATE_HOME/goqobuz and caches in $XDG_CACHE_HOME/goqobuz.
+var (
+	configPath      string
+	stateDir        string
+	cacheDir        string
+	credentialsPath string
+)
+
+func initPaths(configOverride string) error {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return err
+	}
+
+	configPath = filepath.Join(configDir, appName, "config.json")
+	if configOverride != "" {
+		configPath = configOverride
+	}
+
+	stateDir, err = userStateDir()
+	if err != nil {
+		return err
+	}
+	stateDir = filepath.Join(stateDir, appName)
+
+	cacheDir, err = os.UserCacheDir()
+	if err != nil {
+		return err
+	}
+	cacheDir = filepath.Join(cacheDir, appName)
+
+	credentialsPath = filepath.Join(stateDir, "credentials.enc")
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return err
+	}
+
+	return os.MkdirAll(stateDir, 0700)
+}
+
+// userStateDir is $XDG_STATE_HOME, which the os package does not know about.
+// Other systems have no such directory and keep state with the config.
+func userStateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return dir, nil
+	}
+
+	switch runtime.GOOS {
+	case "windows", "darwin", "ios", "plan9":
+		return os.UserConfigDir()
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "state"), nil
+}
+
+func sessionPath(profile string) string {
+	return filepath.Join(stateDir, "sessions", profile+".json")
+}
+
+func defaultDownloadFolder() string {
+	if dir := os.Getenv("XDG_MUSIC_DIR"); dir != "" {
+		return filepath.Join(dir, appName)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "downloads"
+	}
+
+	return filepath.Join(home, "Music", appName)
+}
+
+// migrateLegacyFiles moves the config.json, session and credential files
+// older versions wrote in the working directory to their XDG locations. It
+// does nothing once the new config exists, or when ./config.json is not a
+// goqobuz config. ./config.json is only removed once the copy is valid.
+func migrateLegacyFiles() (bool, error) {
+	if _, err := os.Stat(configPath); err == nil {
+		return false, nil
+	}
+
+	data, err := os.ReadFile("config.json")
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	legacy, _, err := decodeConfig(data)
+	if err != nil || !isGoqobuzConfig(legacy) {
+		return false, nil
+	}
+
+	// Relative download folders were relative to the working directory,
+	// keep them pointing at the same place.
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return false, err
+	}
+
+	absolutize := func(fields map[string]interface{}) {
+		if folder, ok := fields["DownloadFolder"].(string); ok && folder != "" && !filepath.IsAbs(folder) {
+			if abs, err := filepath.Abs(folder); err == nil {
+				fields["DownloadFolder"] = abs
+			}
+		}
+	}
+
+	absolutize(raw)
+	if profiles, ok := raw["Profiles"].(map[string]interface{}); ok {
+		for _, profile := range profiles {
+			if fields, ok := profile.(map[string]interface{}); ok {
+				absolutize(fields)
+			}
+		}
+	}
+
+	data, err = json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return false, err
+	}
+
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		return false, err
+	}
+
+	moves := map[string]string{
+		"session.json":    sessionPath(defaultProfile),
+		"credentials.enc": credentialsPath,
+	}
+
+	// Only the sessions of the config's profiles are goqobuz's.
+	for name := range legacy.Profiles {
+		moves[filepath.Join("sessions", name+".json")] = sessionPath(name)
+	}
+
+	for from, to := range moves {
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(to), 0700); err != nil {
+			return false, err
+		}
+
+		if err := os.Rename(from, to); err != nil {
+			return false, err
+		}
+	}
+
+	// An invalid config is reported by readConfig, keep the original
+	// until it has been fixed.
+	if _, _, err := loadConfig(); err != nil {
+		return true, nil
+	}
+
+	return true, os.Remove("config.json")
+}
+
+// isGoqobuzConfig tells a config written by goqobuz from another tool's
+// config.json that happens to decode: it must have an account.
+func isGoqobuzConfig(config *Config) bool {
+	for _, profile := range config.Profiles {
+		if profile != nil && (profile.Email != "" || profile.Token != "") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupLegacyDir makes a working directory holding files, and points the
+// XDG paths at another directory.
+func setupLegacyDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	state := t.TempDir()
+	configPath = filepath.Join(state, "config", "config.json")
+	stateDir = state
+	credentialsPath = filepath.Join(state, "credentials.enc")
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(name, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestMigrateLegacyFiles(t *testing.T) {
+	dir := setupLegacyDir(t, map[string]string{
+		"config.json":         `{"Email":"user@example.com","Token":"token-1","DownloadFolder":"music"}`,
+		"session.json":        `{"user_auth_token":"token-1"}`,
+		"credentials.enc":     "encrypted",
+		"sessions/other.json": `{"unrelated":true}`,
+	})
+
+	migrated, err := migrateLegacyFiles()
+	if err != nil || !migrated {
+		t.Fatalf("migrateLegacyFiles = %v, %v; want a migration", migrated, err)
+	}
+
+	config, _, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if folder := config.Profiles[defaultProfile].DownloadFolder; folder != filepath.Join(dir, "music") {
+		t.Errorf("DownloadFolder = %q, want it made absolute", folder)
+	}
+
+	if exists("config.json") || exists("session.json") || exists("credentials.enc") {
+		t.Error("legacy files were left in the working directory")
+	}
+
+	if !exists(sessionPath(defaultProfile)) || !exists(credentialsPath) {
+		t.Error("the session and credentials were not moved")
+	}
+
+	if !exists(filepath.Join("sessions", "other.json")) {
+		t.Error("a session of no profile was moved")
+	}
+}
+
+func TestMigrateLegacyFilesLeavesOtherConfigs(t *testing.T) {
+	for name, content := range map[string]string{
+		"not json":      `# config`,
+		"other tool":    `{"name":"project","version":"1.0.0"}`,
+		"empty object":  `{}`,
+		"no account":    `{"DownloadFolder":"music"}`,
+		"newer version": `{"Version":99,"Profiles":{"default":{"Email":"user@example.com"}}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			setupLegacyDir(t, map[string]string{
+				"config.json":     content,
+				"session.json":    `{}`,
+				"credentials.enc": "encrypted",
+			})
+
+			migrated, err := migrateLegacyFiles()
+			if err != nil || migrated {
+				t.Fatalf("migrateLegacyFiles = %v, %v; want nothing done", migrated, err)
+			}
+
+			if exists(configPath) {
+				t.Error("the config was created")
+			}
+
+			if !exists("config.json") || !exists("session.json") || !exists("credentials.enc") {
+				t.Error("files were taken from the working directory")
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyFilesKeepsInvalidConfig(t *testing.T) {
+	setupLegacyDir(t, map[string]string{
+		"config.json": `{"Version":2,"Profiles":{"default":{"Email":"user@example.com","Quality":42}}}`,
+	})
+
+	migrated, err := migrateLegacyFiles()
+	if err != nil || !migrated {
+		t.Fatalf("migrateLegacyFiles = %v, %v; want a migration", migrated, err)
+	}
+
+	if !exists(configPath) || !exists("config.json") {
+		t.Error("an invalid config must be copied, not moved")
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/huh/spinner"
+	"github.com/szerookii/goquobuz/qobuz"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	layoutPlaylist = iota
+	layoutAlbum
+)
+
+// libraryExtensions are the extensions downloads can have, see the MIME
+// types returned by getFileUrl.
+var libraryExtensions = []string{"flac", "mpeg"}
+
+func playlistTrackNaming(track types.Track) trackNaming {
+	return trackNaming{
+		Title:       track.Title,
+		Artist:      track.Performer.Name,
+		Album:       track.Album.Title,
+		TrackNumber: track.TrackNumber,
+		DiscNumber:  track.MediaNumber,
+	}
+}
+
+// existingTrack returns the path of a track already saved under one of the
+// paths pathFor gives for each possible extension.
+func existingTrack(pathFor func(extension string) string) (string, bool) {
+	for _, extension := range libraryExtensions {
+		path := pathFor(extension)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+
+	return "", false
+}
+
+// linkTrack makes target available at path without copying it, with a hard
+// link when possible and a symbolic one otherwise.
+func linkTrack(target, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if err := os.Link(target, path); err == nil {
+		return nil
+	}
+
+	relative, err := filepath.Rel(filepath.Dir(path), target)
+	if err != nil {
+		return err
+	}
+
+	return os.Symlink(relative, path)
+}
+
+// writeM3U8 writes an extended M3U playlist with paths relative to its own
+// folder. Tracks that were not downloaded have an empty path and are left out.
+func writeM3U8(path string, tracks []types.Track, paths []string) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+
+	for i, track := range tracks {
+		if paths[i] == "" {
+			continue
+		}
+
+		relative, err := filepath.Rel(filepath.Dir(path), paths[i])
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&b, "#EXTINF:%d,%s - %s\n%s\n", track.Duration, track.Performer.Name, track.Title, filepath.ToSlash(relative))
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+func downloadPlaylist(ctx context.Context, client *qobuz.QobuzClient, profile *Profile, quality types.Quality) {
+	var query string
+	if err := huh.NewInput().Title("Enter a playlist name/url").Description("This is required to search for a playlist.").Value(&query).Run(); err != nil {
+		return
+	}
+
+	regex := regexp.MustCompile(`https://open.qobuz.com/playlist/(\d+)`)
+	matches := regex.FindStringSubmatch(query)
+
+	var playlistId string
+	if len(matches) > 1 {
+		playlistId = matches[1]
+	} else {
+		result := selectSearchResult(ctx, client, query, searchSelect[types.Playlist]{
+			searchType:  qobuz.SearchPlaylists,
+			noun:        "playlists",
+			title:       "Select a playlist",
+			description: "Choose a playlist to download.",
+			items:       func(r *qobuz.SearchResponse) []types.Playlist { return r.Playlists.Items },
+			label: func(playlist types.Playlist) string {
+				return fmt.Sprintf("%s by %s (%d tracks)", playlist.Name, playlist.Owner.Name, playlist.TracksCount)
+			},
+		})
+		if result == nil {
+			return
+		}
+
+		playlistId = fmt.Sprintf("%d", result.Id)
+	}
+
+	var (
+		playlist *types.Playlist
+		err      error
+	)
+	if err := spinner.New().Title("Fetching playlist...").Action(func() {
+		playlist, err = client.PlaylistContext(ctx, playlistId)
+	}).Run(); err != nil {
+		return
+	}
+
+	if err != nil {
+		fmt.Println("Failed to get playlist:", err)
+		return
+	}
+
+	var layout int
+	if err := huh.NewSelect[int]().Title("Select a layout").Description("Choose where the tracks of "+playlist.Name+" are saved.").Options(
+		huh.NewOption("Playlist folder", layoutPlaylist),
+		huh.NewOption("Album folders", layoutAlbum),
+	).Value(&layout).Run(); err != nil {
+		return
+	}
+
+	var confirm bool
+	if err := huh.NewConfirm().Title("Download playlist").Description(fmt.Sprintf("Are you sure you want to download %s ? It will download up to %d tracks.", playlist.Name, len(playlist.Tracks.Items))).Value(&confirm).Run(); err != nil {
+		return
+	}
+
+	if !confirm {
+		return
+	}
+
+	tracks := playlist.Tracks.Items
+	folder := filepath.Join(profile.DownloadFolder, filenameReplacer.Replace(playlist.Name))
+	m3u8Path := filepath.Join(folder, filenameReplacer.Replace(playlist.Name)+".m3u8")
+	if layout == layoutAlbum {
+		m3u8Path = filepath.Join(profile.DownloadFolder, filenameReplacer.Replace(playlist.Name)+".m3u8")
+	}
+
+	// paths[i] is where tracks[i] ended up, empty when it failed.
+	paths := make([]string, len(tracks))
+
+	downloadAt := func(i int, showProgress bool) error {
+		track := tracks[i]
+		naming := playlistTrackNaming(track)
+
+		albumPath := func(extension string) string {
+			return albumTrackPath(profile, naming, extension)
+		}
+		playlistPath := func(extension string) string {
+			return filepath.Join(folder, trackFilename(profile.NamingTemplate, "{title} - {artist}", naming, extension))
+		}
+
+		pathFor := albumPath
+		if layout == layoutPlaylist {
+			pathFor = playlistPath
+		}
+
+		if path, ok := existingTrack(pathFor); ok {
+			paths[i] = path
+			return nil
+		}
+
+		// Tracks of downloaded albums are linked into the playlist folder.
+		if existing, ok := existingTrack(albumPath); ok {
+			path := playlistPath(strings.TrimPrefix(filepath.Ext(existing), "."))
+			if err := linkTrack(existing, path); err != nil {
+				fmt.Println("Failed to link", track.Title+":", err)
+				return nil
+			}
+
+			fmt.Println("Linked", track.Title+".")
+			paths[i] = path
+			return nil
+		}
+
+		trackURL, err := client.DownloadFileLinkContext(ctx, fmt.Sprintf("%d", track.Id), int(quality))
+		if err != nil {
+			fmt.Println("Failed to get download link for", track.Title+":", err)
+			return nil
+		}
+
+		path := pathFor(strings.Split(trackURL.MimeType, "/")[1])
+
+		fmt.Println("Downloading", track.Title+"...")
+
+		download := downloadFile
+		if showProgress {
+			download = downloadProgress
+		}
+
+		if err := download(ctx, client, trackURL.Url, path); err != nil {
+			fmt.Println("Failed to download", track.Title+":", err)
+			return nil
+		}
+
+		fmt.Printf("Downloaded %s (%d Bit / %.2f kHz).\n", track.Title, trackURL.BitDepth, trackURL.SamplingRate)
+		paths[i] = path
+		return nil
+	}
+
+	forEachConcurrently(len(tracks), profile.Concurrency, downloadAt)
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m3u8Path), 0755); err != nil {
+		fmt.Println("Failed to write playlist:", err)
+		return
+	}
+
+	if err := writeM3U8(m3u8Path, tracks, paths); err != nil {
+		fmt.Println("Failed to write playlist:", err)
+		return
+	}
+
+	saved := 0
+	for _, path := range paths {
+		if path != "" {
+			saved++
+		}
+	}
+
+	fmt.Printf("\nSaved %d of %d tracks of %s, playlist written to %s.\n", saved, len(tracks), playlist.Name, m3u8Path)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testTrack(title, artist string, duration int) types.Track {
+	track := types.Track{Title: title, Duration: duration}
+	track.Performer.Name = artist
+	return track
+}
+
+func TestWriteM3U8(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "playlists", "Road Trip.m3u8")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tracks := []types.Track{
+		testTrack("Come Together", "The Beatles", 259),
+		testTrack("Missing", "Everything but the Girl", 292),
+		testTrack("Dreams", "Fleetwood Mac", 257),
+	}
+	paths := []string{
+		filepath.Join(dir, "The Beatles", "Abbey Road", "01 - Come Together.flac"),
+		"",
+		filepath.Join(dir, "playlists", "Road Trip", "Dreams - Fleetwood Mac.flac"),
+	}
+
+	if err := writeM3U8(path, tracks, paths); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "#EXTM3U\n" +
+		"#EXTINF:259,The Beatles - Come Together\n../The Beatles/Abbey Road/01 - Come Together.flac\n" +
+		"#EXTINF:257,Fleetwood Mac - Dreams\nRoad Trip/Dreams - Fleetwood Mac.flac\n"
+	if string(data) != want {
+		t.Errorf("playlist =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestLinkTrack(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "album", "01 - Track.flac")
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(target, []byte("audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "playlists", "Mix", "Track - Artist.flac")
+	if err := linkTrack(target, path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "audio" {
+		t.Fatalf("read %q, %v through the link; want the target", data, err)
+	}
+
+	if err := linkTrack(target, path); err == nil {
+		t.Error("linking over an existing file succeeded")
+	}
+}
+
+func TestWritePart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "album", "01 - Track.flac")
+
+	errCut := errors.New("connection reset")
+	err := writePart(path, func(file *os.File) error {
+		file.WriteString("aud")
+		return errCut
+	})
+	if !errors.Is(err, errCut) {
+		t.Fatalf("writePart error = %v, want %v", err, errCut)
+	}
+
+	if _, ok := existingTrack(func(extension string) string { return path }); ok {
+		t.Fatal("an interrupted download counts as an existing track")
+	}
+
+	if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+		t.Errorf("the partial file was left behind: %v", err)
+	}
+
+	if err := writePart(path, func(file *os.File) error {
+		_, err := file.WriteString("audio")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := os.ReadFile(path); err != nil || string(data) != "audio" {
+		t.Errorf("read %q, %v; want the whole file", data, err)
+	}
+}
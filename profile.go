@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/huh"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"os"
+	"regexp"
+)
+
+var profileNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// runProfileCommand handles `profile list|add|use|remove [name]`.
+func runProfileCommand(config *Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: profile list|add|use|remove [name]")
+	}
+
+	command, args := args[0], args[1:]
+	if command == "list" {
+		for _, name := range config.profileNames() {
+			current := " "
+			if name == config.CurrentProfile {
+				current = "*"
+			}
+
+			fmt.Println(current, name, "("+config.Profiles[name].Email+")")
+		}
+
+		return nil
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("usage: profile %s <name>", command)
+	}
+	name := args[0]
+
+	switch command {
+	case "add":
+		if !profileNameRegex.MatchString(name) {
+			return errors.New("profile names may only contain letters, digits, - and _")
+		}
+
+		if _, ok := config.Profiles[name]; ok {
+			return fmt.Errorf("profile %q already exists", name)
+		}
+
+		profile, err := promptProfile()
+		if err != nil {
+			return err
+		}
+
+		config.Profiles[name] = profile
+		if config.CurrentProfile == "" {
+			config.CurrentProfile = name
+		}
+
+		fmt.Printf("Added profile %s, you will be asked for its password on first use.\n", name)
+
+	case "use":
+		if _, ok := config.Profiles[name]; !ok {
+			return fmt.Errorf("profile %q does not exist", name)
+		}
+
+		config.CurrentProfile = name
+		fmt.Println("Switched to profile", name+".")
+
+	case "remove":
+		if _, ok := config.Profiles[name]; !ok {
+			return fmt.Errorf("profile %q does not exist", name)
+		}
+
+		delete(config.Profiles, name)
+		if err := os.Remove(sessionPath(name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		if err := deleteSecrets(openKeyring(), name); err != nil {
+			return err
+		}
+
+		if config.CurrentProfile == name {
+			config.CurrentProfile = ""
+			if names := config.profileNames(); len(names) > 0 {
+				config.CurrentProfile = names[0]
+			}
+		}
+
+		fmt.Println("Removed profile", name+".")
+
+	default:
+		return fmt.Errorf("unknown profile command %q", command)
+	}
+
+	return writeConfig(configPath, config)
+}
+
+func promptProfile() (*Profile, error) {
+	profile := newProfile()
+
+	if err := huh.NewInput().Title("Enter the Qobuz email").Value(&profile.Email).Run(); err != nil {
+		return nil, err
+	}
+
+	if err := huh.NewInput().Title("Enter the download folder").Value(&profile.DownloadFolder).Run(); err != nil {
+		return nil, err
+	}
+
+	if err := huh.NewSelect[types.Quality]().Title("Select the preferred quality").Options(
+		huh.NewOption("Best allowed by the subscription", types.Quality(0)),
+		huh.NewOption(types.HiRes24_192.String(), types.HiRes24_192),
+		huh.NewOption(types.HiRes24_96.String(), types.HiRes24_96),
+		huh.NewOption(types.CD16_44.String(), types.CD16_44),
+		huh.NewOption(types.MP3.String(), types.MP3),
+	).Value(&profile.Quality).Run(); err != nil {
+		return nil, err
+	}
+
+	if err := huh.NewInput().Title("Enter a file naming template").Description("Optional, e.g. {track_number} - {title}. Available: {title}, {artist}, {album}, {track_number}, {disc_number}.").Value(&profile.NamingTemplate).Run(); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
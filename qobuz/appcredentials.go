@@ -0,0 +1,185 @@
+package qobuz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const DefaultAppCredentialsTTL = 7 * 24 * time.Hour
+
+// AppCredentials is what GetAppIDAndSecrets extracts from the web player.
+// Secret is the candidate that passed validation, empty until one did.
+type AppCredentials struct {
+	AppID         string    `json:"app_id"`
+	Secrets       []string  `json:"secrets"`
+	Secret        string    `json:"secret,omitempty"`
+	BundleVersion string    `json:"bundle_version"`
+	BaseURL       string    `json:"base_url"`
+	FetchedAt     time.Time `json:"fetched_at"`
+}
+
+// AppCredentialsStore caches AppCredentials between runs. Load returns nil
+// and no error when nothing has been stored yet.
+type AppCredentialsStore interface {
+	Load() (*AppCredentials, error)
+	Save(credentials *AppCredentials) error
+}
+
+type FileAppCredentialsStore struct {
+	Path string
+}
+
+func NewFileAppCredentialsStore(path string) *FileAppCredentialsStore {
+	return &FileAppCredentialsStore{Path: path}
+}
+
+func DefaultAppCredentialsPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "goqobuz", "app_credentials.json"), nil
+}
+
+func (f *FileAppCredentialsStore) Load() (*AppCredentials, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var credentials AppCredentials
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, err
+	}
+
+	return &credentials, nil
+}
+
+func (f *FileAppCredentialsStore) Save(credentials *AppCredentials) error {
+	data, err := json.MarshalIndent(credentials, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(f.Path, data, 0600)
+}
+
+// WithAppCredentialsStore replaces the default file store in the user cache
+// directory. A nil store disables caching.
+func WithAppCredentialsStore(store AppCredentialsStore) Option {
+	return func(s *QobuzClient) {
+		s.appCredentialsStore = store
+	}
+}
+
+func WithAppCredentialsTTL(ttl time.Duration) Option {
+	return func(s *QobuzClient) {
+		s.appCredentialsTTL = ttl
+	}
+}
+
+// WithAppCredentials uses a known app ID and secret instead of scraping the
+// web player. The secret is trusted as is and never refreshed.
+func WithAppCredentials(appID, secret string) Option {
+	return func(s *QobuzClient) {
+		s.manualAppCredentials = &AppCredentials{
+			AppID:   appID,
+			Secrets: []string{secret},
+			Secret:  secret,
+		}
+	}
+}
+
+func defaultAppCredentialsStore() AppCredentialsStore {
+	path, err := DefaultAppCredentialsPath()
+	if err != nil {
+		return nil
+	}
+
+	return NewFileAppCredentialsStore(path)
+}
+
+func (s *QobuzClient) fresh(credentials *AppCredentials) bool {
+	if credentials == nil || credentials.AppID == "" || len(credentials.Secrets) == 0 {
+		return false
+	}
+
+	if credentials.BaseURL != s.baseURL {
+		return false
+	}
+
+	return s.appCredentialsTTL <= 0 || time.Since(credentials.FetchedAt) < s.appCredentialsTTL
+}
+
+// bootstrap loads the app ID and secrets from the store, and only scrapes
+// the web player when they are missing or stale.
+func (s *QobuzClient) bootstrap(ctx context.Context) error {
+	if s.manualAppCredentials != nil {
+		s.setAppCredentials(s.manualAppCredentials)
+		return nil
+	}
+
+	if s.appCredentialsStore != nil {
+		credentials, err := s.appCredentialsStore.Load()
+		if err == nil && s.fresh(credentials) {
+			s.setAppCredentials(credentials)
+			return nil
+		}
+	}
+
+	_, _, err := s.GetAppIDAndSecretsContext(ctx)
+	return err
+}
+
+func (s *QobuzClient) setAppCredentials(credentials *AppCredentials) {
+	s.mu.Lock()
+	s.app_id = credentials.AppID
+	s.secret = credentials.Secret
+	s.appCredentials = credentials
+	s.mu.Unlock()
+}
+
+func (s *QobuzClient) saveAppCredentials(credentials *AppCredentials) {
+	if s.appCredentialsStore == nil {
+		return
+	}
+
+	// The store is only a cache, failing to write it must not fail the client.
+	_ = s.appCredentialsStore.Save(credentials)
+}
+
+// refreshAppCredentials scrapes the web player again after the API rejected
+// a signature made with usedSecret. Concurrent callers share one refresh.
+func (s *QobuzClient) refreshAppCredentials(ctx context.Context, usedSecret string) error {
+	if s.manualAppCredentials != nil {
+		return errors.New("app credentials were set manually")
+	}
+
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	s.mu.RLock()
+	current := s.secret
+	s.mu.RUnlock()
+
+	if current != usedSecret {
+		return nil
+	}
+
+	if _, _, err := s.GetAppIDAndSecretsContext(ctx); err != nil {
+		return err
+	}
+
+	return s.selectSecretLocked(ctx)
+}
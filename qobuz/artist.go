@@ -0,0 +1,77 @@
+package qobuz
+
+import (
+	"context"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Extras of artist/get.
+const (
+	ArtistAlbums          = "albums"
+	ArtistPlaylists       = "playlists"
+	ArtistTracksAppearsOn = "tracks_appears_on"
+)
+
+const artistPageSize = 100
+
+// ArtistOptions selects the extras returned with the artist. Offset and
+// Limit page through the extras, a zero Limit uses the API default.
+type ArtistOptions struct {
+	Extras []string
+	Offset int
+	Limit  int
+}
+
+func (s *QobuzClient) Artist(id string, opts *ArtistOptions) (*types.Artist, error) {
+	return s.ArtistContext(context.Background(), id, opts)
+}
+
+func (s *QobuzClient) ArtistContext(ctx context.Context, id string, opts *ArtistOptions) (*types.Artist, error) {
+	params := url.Values{}
+	params.Set("artist_id", id)
+	if opts != nil {
+		if len(opts.Extras) > 0 {
+			params.Set("extra", strings.Join(opts.Extras, ","))
+		}
+		params.Set("offset", strconv.Itoa(opts.Offset))
+		if opts.Limit > 0 {
+			params.Set("limit", strconv.Itoa(opts.Limit))
+		}
+	}
+
+	artist := &types.Artist{}
+	err := s.api(ctx, "GET", "/artist/get", params, artist)
+	if err != nil {
+		return nil, err
+	}
+
+	return artist, nil
+}
+
+// ArtistAlbums returns every release of the artist, fetching as many pages
+// as needed.
+func (s *QobuzClient) ArtistAlbums(id string) ([]types.Album, error) {
+	return s.ArtistAlbumsContext(context.Background(), id)
+}
+
+func (s *QobuzClient) ArtistAlbumsContext(ctx context.Context, id string) ([]types.Album, error) {
+	var albums []types.Album
+	for {
+		artist, err := s.ArtistContext(ctx, id, &ArtistOptions{
+			Extras: []string{ArtistAlbums},
+			Offset: len(albums),
+			Limit:  artistPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		albums = append(albums, artist.Albums.Items...)
+		if len(artist.Albums.Items) == 0 || len(albums) >= artist.Albums.Total {
+			return albums, nil
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package bundle extracts the app ID and secrets from the Qobuz web player
+// login page and bundle.js. It does no I/O so saved bundles can be parsed
+// offline.
+package bundle
+
+import (
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// The patterns accept the layouts found in testdata: any minified identifier
+// before initialSeed and extra attributes on the script tag.
+var (
+	urlRegex          = regexp.MustCompile(`<script[^>]*\ssrc="(/resources/(\d+\.\d+\.\d+-[a-z]\d{3})/bundle\.js)"[^>]*>`)
+	seedTimezoneRegex = regexp.MustCompile(`[\w$]+\.initialSeed\("(?P<seed>[\w=]+)",window\.utimezone\.(?P<timezone>[a-z]+)\)`)
+	appIDRegex        = regexp.MustCompile(`production:{api:{appId:"(?P<app_id>\d{9})",appSecret:"(\w{32})`)
+)
+
+// infoExtrasPattern is completed with the timezones found in the bundle.
+const infoExtrasPattern = `name:"\w+/(?P<timezone>{timezones})",info:"(?P<info>[\w=]+)",extras:"(?P<extras>[\w=]+)"`
+
+var (
+	ErrURLNotFound   = errors.New("could not find bundle URL")
+	ErrAppIDNotFound = errors.New("could not find app ID")
+	ErrNoSecrets     = errors.New("could not find any secret")
+)
+
+type Info struct {
+	AppID   string
+	Secrets []string
+	Version string
+}
+
+// FindURL returns the path of bundle.js referenced by the login page and
+// its version, e.g. "/resources/7.1.0-b011/bundle.js" and "7.1.0-b011".
+func FindURL(loginPage []byte) (string, string, error) {
+	match := urlRegex.FindSubmatch(loginPage)
+	if match == nil {
+		return "", "", ErrURLNotFound
+	}
+
+	return string(match[1]), string(match[2]), nil
+}
+
+// Parse extracts the app ID and the decoded secrets, in the order they
+// should be tried, from the login page and the bundle it references.
+func Parse(loginPage, bundleJS []byte) (*Info, error) {
+	_, version, err := FindURL(loginPage)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := string(bundleJS)
+
+	appIDMatch := appIDRegex.FindStringSubmatch(bundle)
+	if appIDMatch == nil {
+		return nil, ErrAppIDNotFound
+	}
+
+	secrets := make(map[string][]string)
+	var timezones []string
+	for _, match := range seedTimezoneRegex.FindAllStringSubmatch(bundle, -1) {
+		seed, timezone := match[1], match[2]
+		if _, ok := secrets[timezone]; !ok {
+			timezones = append(timezones, timezone)
+		}
+		secrets[timezone] = []string{seed}
+	}
+
+	// The web player tries the second timezone first.
+	if len(timezones) > 1 {
+		timezones[0], timezones[1] = timezones[1], timezones[0]
+	}
+
+	if len(timezones) == 0 {
+		return nil, ErrNoSecrets
+	}
+
+	var capitalizedTimezones []string
+	for _, timezone := range timezones {
+		capitalizedTimezones = append(capitalizedTimezones, strings.ToUpper(timezone[:1])+timezone[1:])
+	}
+
+	infoExtrasRegex, err := regexp.Compile(strings.Replace(infoExtrasPattern, "{timezones}", strings.Join(capitalizedTimezones, "|"), 1))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, match := range infoExtrasRegex.FindAllStringSubmatch(bundle, -1) {
+		timezone, info, extras := strings.ToLower(match[1]), match[2], match[3]
+		secrets[timezone] = append(secrets[timezone], info, extras)
+	}
+
+	var secretsList []string
+	for _, timezone := range timezones {
+		secret := strings.Join(secrets[timezone], "")
+		if len(secret) > 44 {
+			decodedSecret, err := base64.StdEncoding.DecodeString(secret[:len(secret)-44])
+			if err == nil {
+				secretsList = append(secretsList, string(decodedSecret))
+			}
+		}
+	}
+
+	if len(secretsList) == 0 {
+		return nil, ErrNoSecrets
+	}
+
+	return &Info{
+		AppID:   appIDMatch[1],
+		Secrets: secretsList,
+		Version: version,
+	}, nil
+}
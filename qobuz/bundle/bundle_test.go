@@ -0,0 +1,125 @@
+package bundle
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// expected is testdata/<case>/expected.json. Error names one of the Err*
+// variables when parsing must fail.
+type expected struct {
+	AppID   string   `json:"app_id,omitempty"`
+	Secrets []string `json:"secrets,omitempty"`
+	Version string   `json:"version,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+var errorsByName = map[string]error{
+	"ErrURLNotFound":   ErrURLNotFound,
+	"ErrAppIDNotFound": ErrAppIDNotFound,
+	"ErrNoSecrets":     ErrNoSecrets,
+}
+
+func readFixture(t *testing.T, dir, name string) []byte {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+// TestParse runs every case of testdata. A case without expected.json fails
+// and prints what was parsed, to be checked and saved as expected.json.
+func TestParse(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join("testdata", "*", "login.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dirs) == 0 {
+		t.Fatal("no fixtures in testdata")
+	}
+
+	for _, loginPath := range dirs {
+		dir := filepath.Dir(loginPath)
+
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			loginPage := readFixture(t, dir, "login.html")
+			bundleJS := readFixture(t, dir, "bundle.js")
+
+			info, err := Parse(loginPage, bundleJS)
+
+			got := expected{}
+			if err != nil {
+				got.Error = err.Error()
+				for name, target := range errorsByName {
+					if errors.Is(err, target) {
+						got.Error = name
+					}
+				}
+			} else {
+				got = expected{AppID: info.AppID, Secrets: info.Secrets, Version: info.Version}
+			}
+
+			data := readFixture(t, dir, "expected.json")
+			if data == nil {
+				parsed, _ := json.MarshalIndent(got, "", "  ")
+				t.Fatalf("no expected.json, parsed:\n%s", parsed)
+			}
+
+			var want expected
+			if err := json.Unmarshal(data, &want); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Error != want.Error {
+				t.Fatalf("error = %q, want %q", got.Error, want.Error)
+			}
+
+			if got.AppID != want.AppID {
+				t.Errorf("app ID = %q, want %q", got.AppID, want.AppID)
+			}
+
+			if !reflect.DeepEqual(got.Secrets, want.Secrets) {
+				t.Errorf("secrets = %q, want %q", got.Secrets, want.Secrets)
+			}
+
+			if got.Version != want.Version {
+				t.Errorf("version = %q, want %q", got.Version, want.Version)
+			}
+
+			if want.Error != "" {
+				return
+			}
+
+			// FindURL must point at the bundle the login page references.
+			path, version, err := FindURL(loginPage)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if wantPath := "/resources/" + want.Version + "/bundle.js"; path != wantPath || version != want.Version {
+				t.Errorf("FindURL = %q, %q; want %q, %q", path, version, wantPath, want.Version)
+			}
+		})
+	}
+}
+
+func TestFindURLNotFound(t *testing.T) {
+	for _, page := range []string{
+		"",
+		`<script src="/static/main.js"></script>`,
+		`<script src="/resources/latest/bundle.js"></script>`,
+	} {
+		if _, _, err := FindURL([]byte(page)); !errors.Is(err, ErrURLNotFound) {
+			t.Errorf("FindURL(%q) error = %v, want ErrURLNotFound", page, err)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+function(e,t,n){"use strict";var r={production:{api:{appId:"285473059",appSecret:"6e1d3d5f0b0e4a8c9d2f1e0a7b6c5d4e"}}};a.initialSeed("M2YxYzZhMG",window.utimezone.berlin),b.initialSeed("OWU4ZDdjNm",window.utimezone.london)}
+var o=[{offset:"GMT",name:"Europe/London",info:"I1YTRmM2UyZDFjMGI5YT",extras:"hmN2U2ZDVjNGI=xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"},{offset:"GMT+01:00",name:"Europe/Berlin",info:"U1YjJkNGM3ZThmOWEwYj",extras:"FjMmQzZTRmNTA=xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"}];
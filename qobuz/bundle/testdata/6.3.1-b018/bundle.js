@@ -0,0 +1,2 @@
+var config={production:{api:{appId:"950096963",appSecret:"aa11bb22cc33dd44ee55ff6677889900"}}};r.initialSeed("MGExYjJjM2",window.utimezone.algiers),i.initialSeed("ZjBlMWQyYz",window.utimezone.berlin),o.initialSeed("MTEyMjMzND",window.utimezone.london);
+{name:"Africa/Algiers",info:"Q0ZTVmNjA3MTgyOTNhNG",extras:"I1YzZkN2U4Zjk=xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"},{name:"Europe/Berlin",info:"NiNGE1OTY4Nzc4Njk1YT",extras:"RiM2MyZDFlMGY=xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"},{name:"Europe/London",info:"Q1NTY2Nzc4ODk5MDBhYW",extras:"JiY2NkZGVlZmY=xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"}
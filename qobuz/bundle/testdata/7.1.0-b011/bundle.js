@@ -0,0 +1,2 @@
+const Qe={production:{api:{appId:"798273057",appSecret:"0123456789abcdef0123456789abcdef"}},staging:{api:{appId:"100000000"}}};$e.initialSeed("NWQ0MTQwMm",window.utimezone.abidjan);Mt.initialSeed("N2Q3OTMwMz",window.utimezone.montreal);Mt.initialSeed("dW51c2Vk",window.utimezone.tokyo);
+[{name:"America/Montreal",info:"dhMDc2MDE4NjU3NGIwMj",extras:"gyZjJmNDM1ZTc=xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"},{name:"Africa/Abidjan",info:"FiYzRiMmE3NmI5NzE5ZD",extras:"kxMTAxN2M1OTI=xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"}]
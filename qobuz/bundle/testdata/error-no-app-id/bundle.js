@@ -0,0 +1 @@
+a.initialSeed("NWQ0MTQwMm",window.utimezone.abidjan);{name:"Africa/Abidjan",info:"FiYzRiMmE3NmI5NzE5ZD",extras:"kxMTAxN2M1OTI=xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"}
@@ -0,0 +1 @@
+var r={production:{api:{appId:"798273057",appSecret:"0123456789abcdef0123456789abcdef"}}};
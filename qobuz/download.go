@@ -1,9 +1,10 @@
 package qobuz
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -26,8 +27,36 @@ type TrackURLResponse struct {
 }
 
 func (s *QobuzClient) DownloadFileLink(trackID string, quality int) (*TrackURLResponse, error) {
+	return s.DownloadFileLinkContext(context.Background(), trackID, quality)
+}
+
+func (s *QobuzClient) DownloadFileLinkContext(ctx context.Context, trackID string, quality int) (*TrackURLResponse, error) {
+	secret, err := s.validSecret(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trackURLResponse, err := s.fileURL(ctx, trackID, quality, secret)
+	if !errors.Is(err, ErrInvalidSignature) {
+		return trackURLResponse, err
+	}
+
+	// The cached secret may be outdated, scrape a new one and try again once.
+	if refreshErr := s.refreshAppCredentials(ctx, secret); refreshErr != nil {
+		return nil, err
+	}
+
+	secret, err = s.validSecret(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.fileURL(ctx, trackID, quality, secret)
+}
+
+func (s *QobuzClient) fileURL(ctx context.Context, trackID string, quality int, secret string) (*TrackURLResponse, error) {
 	unixTS := strconv.FormatInt(time.Now().Unix(), 10)
-	rSig := fmt.Sprintf("trackgetFileUrlformat_id%dintentstreamtrack_id%s%s%s", quality, trackID, unixTS, s.secret)
+	rSig := fmt.Sprintf("trackgetFileUrlformat_id%dintentstreamtrack_id%s%s%s", quality, trackID, unixTS, secret)
 
 	hasher := md5.New()
 	hasher.Write([]byte(rSig))
@@ -40,25 +69,50 @@ func (s *QobuzClient) DownloadFileLink(trackID string, quality int) (*TrackURLRe
 	params.Set("format_id", strconv.Itoa(quality))
 	params.Set("intent", "stream")
 
-	req, err := http.NewRequest("GET", APIBaseURL+"/track/getFileUrl?"+params.Encode(), nil)
+	var trackURLResponse *TrackURLResponse
+	err := s.api(ctx, "GET", "/track/getFileUrl", params, &trackURLResponse)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("X-App-Id", s.app_id)
-	req.Header.Set("X-User-Auth-Token", s.authToken)
+	if trackURLResponse == nil || trackURLResponse.Url == "" {
+		apiErr := &APIError{
+			StatusCode: http.StatusOK,
+			Endpoint:   "/track/getFileUrl",
+			Message:    "no file url returned",
+		}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
+		if trackURLResponse != nil {
+			for _, restriction := range trackURLResponse.Restrictions {
+				apiErr.Restrictions = append(apiErr.Restrictions, restriction.Code)
+			}
+		}
+
+		return nil, apiErr
 	}
 
-	defer resp.Body.Close()
-	var trackURLResponse *TrackURLResponse
-	err = json.NewDecoder(resp.Body).Decode(&trackURLResponse)
+	return trackURLResponse, nil
+}
+
+func (s *QobuzClient) Stream(fileURL string) (*http.Response, error) {
+	return s.StreamContext(context.Background(), fileURL)
+}
+
+// StreamContext opens the audio file returned by DownloadFileLink. The body
+// is bound to ctx, so cancelling it aborts the transfer. The caller must close
+// the response body.
+func (s *QobuzClient) StreamContext(ctx context.Context, fileURL string) (*http.Response, error) {
+	resp, err := s.doRetry(ctx, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", fileURL, nil)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return trackURLResponse, nil
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("receiving status of %d for url: %s", resp.StatusCode, fileURL)
+	}
+
+	return resp, nil
 }
@@ -0,0 +1,90 @@
+package qobuz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrNotFound         = errors.New("not found")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrInvalidSignature = errors.New("invalid request signature")
+	ErrRegionRestricted = errors.New("restricted in this region")
+	ErrNoValidSecret    = errors.New("none of the extracted secrets is valid")
+)
+
+// APIError is returned for every non-2xx answer of the Qobuz API, and for
+// file URL requests that came back without a URL. It matches the Err*
+// sentinels with errors.Is.
+type APIError struct {
+	StatusCode   int
+	Code         int
+	Message      string
+	Endpoint     string
+	Restrictions []string
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+
+	if len(e.Restrictions) > 0 {
+		msg += " (" + strings.Join(e.Restrictions, ", ") + ")"
+	}
+
+	return fmt.Sprintf("%s: %d %s", e.Endpoint, e.StatusCode, msg)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.Code == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.Code == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests || e.Code == http.StatusTooManyRequests
+	case ErrInvalidSignature:
+		return e.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(e.Message), "signature")
+	case ErrRegionRestricted:
+		for _, restriction := range e.Restrictions {
+			if restriction == "TrackRestrictedByRightHolders" {
+				return true
+			}
+		}
+
+		msg := strings.ToLower(e.Message)
+		return strings.Contains(msg, "not available in your") || strings.Contains(msg, "region")
+	}
+
+	return false
+}
+
+func newAPIError(endpoint string, resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Endpoint:   endpoint,
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return apiErr
+	}
+
+	var errorResponse struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &errorResponse) == nil {
+		apiErr.Code = errorResponse.Code
+		apiErr.Message = errorResponse.Message
+	}
+
+	return apiErr
+}
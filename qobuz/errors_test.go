@@ -0,0 +1,81 @@
+package qobuz
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	sentinels := []error{ErrUnauthorized, ErrNotFound, ErrRateLimited, ErrInvalidSignature, ErrRegionRestricted, ErrNoValidSecret}
+
+	for _, test := range []struct {
+		name string
+		err  *APIError
+		want []error
+	}{
+		{"401 status", &APIError{StatusCode: 401}, []error{ErrUnauthorized}},
+		{"401 code in a 400", &APIError{StatusCode: 400, Code: 401, Message: "User authentication is required."}, []error{ErrUnauthorized}},
+		{"404 status", &APIError{StatusCode: 404}, []error{ErrNotFound}},
+		{"404 code", &APIError{StatusCode: 400, Code: 404}, []error{ErrNotFound}},
+		{"429 status", &APIError{StatusCode: 429}, []error{ErrRateLimited}},
+		{"429 code", &APIError{StatusCode: 400, Code: 429}, []error{ErrRateLimited}},
+		{"invalid signature", &APIError{StatusCode: 400, Code: 400, Message: "Invalid Request Signature parameter (request_sig)"}, []error{ErrInvalidSignature}},
+		{"signature in lower case", &APIError{StatusCode: 400, Message: "bad signature"}, []error{ErrInvalidSignature}},
+		{"signature outside a 400", &APIError{StatusCode: 401, Message: "Invalid Request Signature parameter (request_sig)"}, []error{ErrUnauthorized}},
+		{"other 400", &APIError{StatusCode: 400, Message: "Invalid or missing format_id"}, nil},
+		{"restricted track", &APIError{StatusCode: 200, Restrictions: []string{"FormatRestrictedByFormatAvailability", "TrackRestrictedByRightHolders"}}, []error{ErrRegionRestricted}},
+		{"other restriction", &APIError{StatusCode: 200, Restrictions: []string{"FormatRestrictedByFormatAvailability"}}, nil},
+		{"not available message", &APIError{StatusCode: 403, Message: "This track is not available in your country"}, []error{ErrRegionRestricted}},
+		{"region message", &APIError{StatusCode: 403, Message: "Region restricted"}, []error{ErrRegionRestricted}},
+		{"server error", &APIError{StatusCode: 500}, nil},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			for _, sentinel := range sentinels {
+				want := false
+				for _, target := range test.want {
+					want = want || target == sentinel
+				}
+
+				if got := errors.Is(test.err, sentinel); got != want {
+					t.Errorf("errors.Is(%v, %q) = %v, want %v", test.err, sentinel, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewAPIError(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		status  int
+		body    string
+		code    int
+		message string
+		text    string
+	}{
+		{"JSON body", 400, `{"status":"error","code":400,"message":"Invalid Request Signature parameter (request_sig)"}`, 400, "Invalid Request Signature parameter (request_sig)", "/track/getFileUrl: 400 Invalid Request Signature parameter (request_sig)"},
+		{"HTML body", 502, `<html><body>Bad Gateway</body></html>`, 0, "", "/track/getFileUrl: 502 Bad Gateway"},
+		{"empty body", 401, ``, 0, "", "/track/getFileUrl: 401 Unauthorized"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: test.status, Body: io.NopCloser(strings.NewReader(test.body))}
+
+			err := newAPIError("/track/getFileUrl", resp)
+			if err.StatusCode != test.status || err.Code != test.code || err.Message != test.message {
+				t.Errorf("newAPIError = %+v, want status %d, code %d and message %q", err, test.status, test.code, test.message)
+			}
+
+			if err.Error() != test.text {
+				t.Errorf("Error() = %q, want %q", err.Error(), test.text)
+			}
+		})
+	}
+
+	err := &APIError{StatusCode: 200, Endpoint: "/track/getFileUrl", Restrictions: []string{"TrackRestrictedByRightHolders"}}
+	if want := "/track/getFileUrl: 200 OK (TrackRestrictedByRightHolders)"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,66 @@
+package qobuz
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultUserAgent = "Qobuz/5.2.0 Android/29"
+
+type Option func(*QobuzClient)
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(s *QobuzClient) {
+		if httpClient != nil {
+			s.httpClient = httpClient
+		}
+	}
+}
+
+// WithBaseURL overrides the web player URL the app ID and secrets are scraped from.
+func WithBaseURL(baseURL string) Option {
+	return func(s *QobuzClient) {
+		s.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+func WithAPIBaseURL(apiBaseURL string) Option {
+	return func(s *QobuzClient) {
+		s.apiBaseURL = strings.TrimSuffix(apiBaseURL, "/")
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(s *QobuzClient) {
+		s.userAgent = userAgent
+	}
+}
+
+// WithTimeout bounds every API request, retries included, and the web player
+// scraping. Streams are only bound by the context given to StreamContext, so
+// long downloads are never cut off.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *QobuzClient) {
+		s.timeout = timeout
+	}
+}
+
+func newClient(opts []Option) *QobuzClient {
+	c := &QobuzClient{
+		httpClient:  http.DefaultClient,
+		baseURL:     BaseURL,
+		apiBaseURL:  APIBaseURL,
+		userAgent:   DefaultUserAgent,
+		retryPolicy: DefaultRetryPolicy,
+
+		appCredentialsStore: defaultAppCredentialsStore(),
+		appCredentialsTTL:   DefaultAppCredentialsTTL,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
@@ -0,0 +1,89 @@
+package qobuz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOptionsAgainstFakeServer(t *testing.T) {
+	fake := newFakeQobuz(t)
+
+	var userAgent string
+	fake.handle("/catalog/search", func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		fmt.Fprint(w, `{"query":"q","albums":{"total":1,"items":[{"id":"a1","title":"A"}]}}`)
+	})
+
+	client, err := NewFromCredentials("user@example.com", "password", fake.options(WithUserAgent("goqobuz-test"))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.Search("q")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Albums.Items) != 1 || response.Albums.Items[0].Id != "a1" {
+		t.Errorf("unexpected search response: %+v", response.Albums)
+	}
+
+	if userAgent != "goqobuz-test" {
+		t.Errorf("User-Agent = %q, want goqobuz-test", userAgent)
+	}
+}
+
+func TestTimeoutBoundsAPIRequests(t *testing.T) {
+	fake := newFakeQobuz(t)
+	fake.handle("/album/get", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+
+	client, err := NewFromCredentials("user@example.com", "password", fake.options(WithTimeout(50*time.Millisecond))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Album("a1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Album error = %v, want a deadline exceeded error", err)
+	}
+}
+
+func TestTimeoutDoesNotCutStreams(t *testing.T) {
+	fake := newFakeQobuz(t)
+
+	file := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "5")
+		for _, b := range []byte("audio") {
+			w.Write([]byte{b})
+			w.(http.Flusher).Flush()
+			time.Sleep(40 * time.Millisecond)
+		}
+	}))
+	t.Cleanup(file.Close)
+
+	client, err := NewFromCredentials("user@example.com", "password", fake.options(WithTimeout(50*time.Millisecond))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.Stream(file.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "audio" {
+		t.Fatalf("read %q, %v; want the whole file", body, err)
+	}
+}
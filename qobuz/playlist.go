@@ -0,0 +1,133 @@
+package qobuz
+
+import (
+	"context"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"net/url"
+	"strconv"
+)
+
+const playlistPageSize = 500
+
+// PlaylistIterator walks the tracks of a playlist one page at a time:
+//
+//	it := client.PlaylistTracks(id)
+//	for it.Next() {
+//		track := it.Track()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type PlaylistIterator struct {
+	client   *QobuzClient
+	ctx      context.Context
+	id       string
+	playlist *types.Playlist
+	page     []types.Track
+	index    int
+	offset   int
+	done     bool
+	err      error
+}
+
+func (s *QobuzClient) PlaylistTracks(id string) *PlaylistIterator {
+	return s.PlaylistTracksContext(context.Background(), id)
+}
+
+func (s *QobuzClient) PlaylistTracksContext(ctx context.Context, id string) *PlaylistIterator {
+	return &PlaylistIterator{client: s, ctx: ctx, id: id, index: -1}
+}
+
+// Next advances to the next track, fetching the next page when needed. It
+// returns false at the end of the playlist or on error, see Err.
+func (it *PlaylistIterator) Next() bool {
+	if it.err != nil || it.done {
+		return false
+	}
+
+	it.index++
+	if it.index < len(it.page) {
+		return true
+	}
+
+	if it.playlist != nil && it.offset >= it.playlist.Tracks.Total {
+		it.done = true
+		return false
+	}
+
+	playlist, err := it.client.playlistPage(it.ctx, it.id, it.offset, playlistPageSize)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.playlist = playlist
+	it.page = playlist.Tracks.Items
+	it.index = 0
+	it.offset += len(it.page)
+
+	// Guard against a total larger than what the API actually returns.
+	if len(it.page) == 0 {
+		it.done = true
+		return false
+	}
+
+	return true
+}
+
+// Track returns the current track.
+func (it *PlaylistIterator) Track() types.Track {
+	return it.page[it.index]
+}
+
+// Playlist returns the playlist details, nil before the first call to Next.
+// Its Tracks only hold the current page.
+func (it *PlaylistIterator) Playlist() *types.Playlist {
+	return it.playlist
+}
+
+func (it *PlaylistIterator) Err() error {
+	return it.err
+}
+
+// Playlist returns the playlist with all its tracks, fetching as many pages
+// as needed. Use PlaylistTracks to avoid loading them all at once.
+func (s *QobuzClient) Playlist(id string) (*types.Playlist, error) {
+	return s.PlaylistContext(context.Background(), id)
+}
+
+func (s *QobuzClient) PlaylistContext(ctx context.Context, id string) (*types.Playlist, error) {
+	var tracks []types.Track
+
+	it := s.PlaylistTracksContext(ctx, id)
+	for it.Next() {
+		tracks = append(tracks, it.Track())
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	playlist := it.Playlist()
+	playlist.Tracks.Offset = 0
+	playlist.Tracks.Limit = len(tracks)
+	playlist.Tracks.Items = tracks
+
+	return playlist, nil
+}
+
+func (s *QobuzClient) playlistPage(ctx context.Context, id string, offset, limit int) (*types.Playlist, error) {
+	params := url.Values{}
+	params.Set("playlist_id", id)
+	params.Set("extra", "tracks")
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("limit", strconv.Itoa(limit))
+
+	playlist := &types.Playlist{}
+	err := s.api(ctx, "GET", "/playlist/get", params, playlist)
+	if err != nil {
+		return nil, err
+	}
+
+	return playlist, nil
+}
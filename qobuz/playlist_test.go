@@ -0,0 +1,159 @@
+package qobuz
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// servePlaylist makes the fake answer playlist/get for a playlist of total
+// tracks, of which only the first available are actually returned. It
+// returns the offsets requested.
+func servePlaylist(fake *fakeQobuz, total, available int) func() []int {
+	var (
+		mu      sync.Mutex
+		offsets []int
+	)
+
+	fake.handle("/playlist/get", func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		mu.Lock()
+		offsets = append(offsets, offset)
+		mu.Unlock()
+
+		type track struct {
+			Id    int    `json:"id"`
+			Title string `json:"title"`
+		}
+
+		items := []track{}
+		for i := offset; i < min(offset+limit, available); i++ {
+			items = append(items, track{Id: i + 1, Title: "Track " + strconv.Itoa(i+1)})
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":           42,
+			"name":         "Mix",
+			"tracks_count": total,
+			"tracks": map[string]interface{}{
+				"offset": offset,
+				"limit":  limit,
+				"total":  total,
+				"items":  items,
+			},
+		})
+	})
+
+	return func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return offsets
+	}
+}
+
+func TestPlaylistPages(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		total, available int
+		offsets          []int
+	}{
+		{"empty", 0, 0, []int{0}},
+		{"one page", 120, 120, []int{0}},
+		{"exactly one page", 500, 500, []int{0}},
+		{"exact multiple of the page size", 1000, 1000, []int{0, 500}},
+		{"several pages", 1234, 1234, []int{0, 500, 1000}},
+		{"total larger than the tracks returned", 1200, 700, []int{0, 500, 700}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fake := newFakeQobuz(t)
+			requested := servePlaylist(fake, test.total, test.available)
+
+			client, err := NewFromCredentials("user@example.com", "password", fake.options()...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			count := 0
+			it := client.PlaylistTracks("42")
+			for it.Next() {
+				count++
+				if track := it.Track(); track.Id != count {
+					t.Fatalf("track %d has ID %d", count, track.Id)
+				}
+			}
+
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			if count != test.available {
+				t.Errorf("iterated %d tracks, want %d", count, test.available)
+			}
+
+			if it.Playlist() == nil || it.Playlist().Name != "Mix" {
+				t.Errorf("Playlist() = %+v, want the playlist details", it.Playlist())
+			}
+
+			if it.Next() {
+				t.Error("Next returned true after the end")
+			}
+
+			if offsets := requested(); !equalInts(offsets, test.offsets) {
+				t.Errorf("requested offsets %v, want %v", offsets, test.offsets)
+			}
+
+			playlist, err := client.Playlist("42")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(playlist.Tracks.Items) != test.available || playlist.Tracks.Limit != test.available || playlist.Tracks.Offset != 0 {
+				t.Errorf("Playlist holds %d tracks (offset %d, limit %d), want all %d", len(playlist.Tracks.Items), playlist.Tracks.Offset, playlist.Tracks.Limit, test.available)
+			}
+
+			if playlist.Tracks.Total != test.total {
+				t.Errorf("Playlist total = %d, want %d", playlist.Tracks.Total, test.total)
+			}
+		})
+	}
+}
+
+func TestPlaylistTracksError(t *testing.T) {
+	fake := newFakeQobuz(t)
+	fake.handle("/playlist/get", func(w http.ResponseWriter, r *http.Request) {
+		apiError(w, http.StatusNotFound, "Playlist not found")
+	})
+
+	client, err := NewFromCredentials("user@example.com", "password", fake.options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it := client.PlaylistTracks("42")
+	if it.Next() || it.Err() == nil {
+		t.Fatalf("Next on a missing playlist = true or no error (%v)", it.Err())
+	}
+
+	if _, err := client.Playlist("42"); err == nil {
+		t.Error("Playlist of a missing playlist succeeded")
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,22 +1,16 @@
 package qobuz
 
 import (
-	"encoding/base64"
-	"encoding/json"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
-	"fmt"
+	"github.com/szerookii/goquobuz/qobuz/bundle"
 	"github.com/szerookii/goquobuz/qobuz/types"
-	"io/ioutil"
 	"net/http"
 	"net/url"
-	"regexp"
-	"strings"
-)
-
-var (
-	seedTimezoneRegex = regexp.MustCompile(`[a-z]\.initialSeed\("(?P<seed>[\w=]+)",window\.utimezone\.(?P<timezone>[a-z]+)\)`)
-	infoExtrasRegex   = regexp.MustCompile(`name:"\w+/(?P<timezone>{timezones})",info:"(?P<info>[\w=]+)",extras:"(?P<extras>[\w=]+)"`)
-	appIDRegex        = regexp.MustCompile(`production:{api:{appId:"(?P<app_id>\d{9})",appSecret:"(\w{32})`)
+	"sync"
+	"time"
 )
 
 const (
@@ -25,15 +19,44 @@ const (
 	LoginURL   = APIBaseURL + "/user/login"
 )
 
+// QobuzClient is safe for concurrent use by multiple goroutines once it has
+// been returned by one of the constructors. Its session state (app ID,
+// secret, token and user) is guarded by mu and may be refreshed while other
+// requests are in flight.
 type QobuzClient struct {
-	email     string
-	password  string
-	user      *types.User
-	authToken string
-	loggedIn  bool
+	mu      sync.RWMutex
+	loginMu sync.Mutex
+
+	email        string
+	passwordHash string
+	user         *types.User
+	authToken    string
+	loggedIn     bool
 
 	app_id string
 	secret string
+
+	httpClient *http.Client
+	baseURL    string
+	apiBaseURL string
+	userAgent  string
+	timeout    time.Duration
+
+	retryPolicy RetryPolicy
+
+	appCredentials       *AppCredentials
+	manualAppCredentials *AppCredentials
+	appCredentialsStore  AppCredentialsStore
+	appCredentialsTTL    time.Duration
+	refreshMu            sync.Mutex
+
+	sessionStore     SessionStore
+	tokenRefreshHook func(session *Session)
+	reauthMu         sync.Mutex
+
+	metadataLimiter   *RateLimiter
+	fileURLLimiter    *RateLimiter
+	rateLimitObserver func(endpoint string, waited time.Duration)
 }
 
 type LoginReponse struct {
@@ -41,18 +64,41 @@ type LoginReponse struct {
 	UserAuthToken string      `json:"user_auth_token"`
 }
 
-func NewFromCredentials(email, password string) (*QobuzClient, error) {
-	c := &QobuzClient{
-		email:    email,
-		password: password,
+func NewFromCredentials(email, password string, opts ...Option) (*QobuzClient, error) {
+	return NewFromCredentialsContext(context.Background(), email, password, opts...)
+}
+
+func NewFromCredentialsContext(ctx context.Context, email, password string, opts ...Option) (*QobuzClient, error) {
+	return NewFromPasswordHashContext(ctx, email, HashPassword(password), opts...)
+}
+
+// HashPassword returns the MD5 hex digest Qobuz expects instead of the
+// plaintext password.
+func HashPassword(password string) string {
+	sum := md5.Sum([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+func NewFromPasswordHash(email, passwordHash string, opts ...Option) (*QobuzClient, error) {
+	return NewFromPasswordHashContext(context.Background(), email, passwordHash, opts...)
+}
+
+func NewFromPasswordHashContext(ctx context.Context, email, passwordHash string, opts ...Option) (*QobuzClient, error) {
+	c := newClient(opts)
+	c.email = email
+	c.passwordHash = passwordHash
+
+	err := c.bootstrap(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	_, _, err := c.GetAppIDAndSecrets()
+	err = c.LoginContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	err = c.Login()
+	err = c.selectSecret(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -60,12 +106,25 @@ func NewFromCredentials(email, password string) (*QobuzClient, error) {
 	return c, nil
 }
 
-func NewFromAuthToken(authToken string) (*QobuzClient, error) {
-	c := &QobuzClient{
-		authToken: authToken,
+func NewFromAuthToken(authToken string, opts ...Option) (*QobuzClient, error) {
+	return NewFromAuthTokenContext(context.Background(), authToken, opts...)
+}
+
+func NewFromAuthTokenContext(ctx context.Context, authToken string, opts ...Option) (*QobuzClient, error) {
+	c := newClient(opts)
+	c.authToken = authToken
+
+	err := c.bootstrap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.LoginWithTokenContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	_, _, err := c.GetAppIDAndSecrets()
+	err = c.selectSecret(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -74,36 +133,28 @@ func NewFromAuthToken(authToken string) (*QobuzClient, error) {
 }
 
 func (s *QobuzClient) Login() error {
-	if s.loggedIn {
-		return errors.New("already logged in")
-	}
+	return s.LoginContext(context.Background())
+}
 
-	params := url.Values{}
-	params.Set("app_id", s.app_id)
-	params.Set("email", s.email)
-	params.Set("password", s.password)
+func (s *QobuzClient) LoginContext(ctx context.Context) error {
+	s.loginMu.Lock()
+	defer s.loginMu.Unlock()
 
-	req, err := http.NewRequest("POST", LoginURL+"?"+params.Encode(), nil)
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Qobuz/5.2.0 Android/29")
+	s.mu.RLock()
+	loggedIn, appID := s.loggedIn, s.app_id
+	s.mu.RUnlock()
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
+	if loggedIn {
+		return errors.New("already logged in")
 	}
 
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("login failed with status code %d", resp.StatusCode)
-	}
+	params := url.Values{}
+	params.Set("app_id", appID)
+	params.Set("email", s.email)
+	params.Set("password", s.passwordHash)
 
 	var loginResponse LoginReponse
-	err = json.NewDecoder(resp.Body).Decode(&loginResponse)
+	err := s.api(ctx, "POST", "/user/login", params, &loginResponse)
 	if err != nil {
 		return err
 	}
@@ -112,151 +163,96 @@ func (s *QobuzClient) Login() error {
 		return errors.New("login failed")
 	}
 
-	s.user = loginResponse.User
-	s.authToken = loginResponse.UserAuthToken
-
-	s.loggedIn = true
+	s.setLogin(&loginResponse)
 
 	return nil
 }
 
-func (s *QobuzClient) GetAppIDAndSecrets() (string, []string, error) {
-	resp, err := http.Get("https://play.qobuz.com/login")
-	if err != nil {
-		return "", nil, err
-	}
-	defer resp.Body.Close()
-	loginPage, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", nil, err
-	}
-
-	bundleURLRegex := regexp.MustCompile(`<script src="(/resources/\d+\.\d+\.\d+-[a-z]\d{3}/bundle\.js)"></script>`)
-	bundleURLMatch := bundleURLRegex.FindSubmatch(loginPage)
-	if bundleURLMatch == nil {
-		return "", nil, fmt.Errorf("could not find bundle URL")
-	}
-	bundleURL := string(bundleURLMatch[1])
-
-	resp, err = http.Get("https://play.qobuz.com" + bundleURL)
-	if err != nil {
-		return "", nil, err
-	}
+func (s *QobuzClient) LoginWithToken() error {
+	return s.LoginWithTokenContext(context.Background())
+}
 
-	defer resp.Body.Close()
-	bundleString, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", nil, err
-	}
+// LoginWithTokenContext checks the auth token the client was created with
+// and fetches the user profile, like a password login would. A rejected
+// token gives an error matching ErrUnauthorized.
+func (s *QobuzClient) LoginWithTokenContext(ctx context.Context) error {
+	s.loginMu.Lock()
+	defer s.loginMu.Unlock()
 
-	bundle := string(bundleString)
+	s.mu.RLock()
+	appID, authToken := s.app_id, s.authToken
+	s.mu.RUnlock()
 
-	appIDMatch := appIDRegex.FindStringSubmatch(bundle)
-	if appIDMatch == nil {
-		return "", nil, fmt.Errorf("could not find app ID")
+	if authToken == "" {
+		return errors.New("no auth token")
 	}
-	appID := appIDMatch[1]
 
-	secrets := make(map[string][]string)
-	seedMatches := seedTimezoneRegex.FindAllStringSubmatch(bundle, -1)
-	for _, match := range seedMatches {
-		seed, timezone := match[1], match[2]
-		secrets[timezone] = []string{seed}
-	}
+	params := url.Values{}
+	params.Set("app_id", appID)
+	params.Set("user_auth_token", authToken)
 
-	var orderedSecrets []string
-	for timezone, _ := range secrets {
-		orderedSecrets = append(orderedSecrets, timezone)
-	}
-	if len(orderedSecrets) > 1 {
-		orderedSecrets[0], orderedSecrets[1] = orderedSecrets[1], orderedSecrets[0]
+	var loginResponse LoginReponse
+	err := s.api(ctx, "POST", "/user/login", params, &loginResponse)
+	if err != nil {
+		return err
 	}
 
-	var capitalizedTimezones []string
-	for _, timezone := range orderedSecrets {
-		capitalizedTimezones = append(capitalizedTimezones, strings.Title(timezone))
+	if loginResponse.User == nil {
+		return &APIError{StatusCode: http.StatusUnauthorized, Endpoint: "/user/login", Message: "token rejected"}
 	}
 
-	infoExtrasRegex = regexp.MustCompile(strings.Replace(infoExtrasRegex.String(), "{timezones}", strings.Join(capitalizedTimezones, "|"), 1))
-
-	infoExtrasMatches := infoExtrasRegex.FindAllStringSubmatch(bundle, -1)
-	for _, match := range infoExtrasMatches {
-		timezone, info, extras := strings.ToLower(match[1]), match[2], match[3]
-		secrets[timezone] = append(secrets[timezone], info, extras)
+	if loginResponse.UserAuthToken == "" {
+		loginResponse.UserAuthToken = authToken
 	}
 
-	var secretsList []string
-	for _, timezone := range orderedSecrets {
-		secret := strings.Join(secrets[timezone], "")
-		if len(secret) > 44 {
-			decodedSecret, err := base64.StdEncoding.DecodeString(secret[:len(secret)-44])
-			if err == nil {
-				secretsList = append(secretsList, string(decodedSecret))
-			}
-		}
-	}
+	s.setLogin(&loginResponse)
 
-	s.app_id = appID
-	s.secret = secretsList[len(secretsList)-1]
+	return nil
+}
 
-	return appID, secretsList, nil
+func (s *QobuzClient) setLogin(loginResponse *LoginReponse) {
+	s.mu.Lock()
+	s.user = loginResponse.User
+	s.authToken = loginResponse.UserAuthToken
+	s.loggedIn = true
+	s.mu.Unlock()
 }
 
-type SearchResponse struct {
-	Query  string `json:"query"`
-	Albums struct {
-		Limit  int           `json:"limit"`
-		Offset int           `json:"offset"`
-		Total  int           `json:"total"`
-		Items  []types.Album `json:"items"`
-	} `json:"albums"`
-	Tracks struct {
-		Limit  int           `json:"limit"`
-		Offset int           `json:"offset"`
-		Total  int           `json:"total"`
-		Items  []types.Track `json:"items"`
-	} `json:"tracks"`
-	Artists struct {
-		Limit  int           `json:"limit"`
-		Offset int           `json:"offset"`
-		Total  int           `json:"total"`
-		Items  []interface{} `json:"items"` // TODO: Implement artists
-	} `json:"artists"`
-	Playlists struct {
-		Limit  int           `json:"limit"`
-		Offset int           `json:"offset"`
-		Total  int           `json:"total"`
-		Items  []interface{} `json:"items"` // TODO: Implement playlists
-	} `json:"playlists"`
-	Stories struct {
-		Limit  int           `json:"limit"`
-		Offset int           `json:"offset"`
-		Total  int           `json:"total"`
-		Items  []interface{} `json:"items"` // TODO: Implement stories
-	} `json:"stories"`
+func (s *QobuzClient) GetAppIDAndSecrets() (string, []string, error) {
+	return s.GetAppIDAndSecretsContext(context.Background())
 }
 
-func (s *QobuzClient) Search(query string) (*SearchResponse, error) {
-	req, err := http.NewRequest("GET", APIBaseURL+"/catalog/search?query="+url.QueryEscape(query), nil)
+func (s *QobuzClient) GetAppIDAndSecretsContext(ctx context.Context) (string, []string, error) {
+	loginPage, err := s.fetch(ctx, s.baseURL+"/login")
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
-	req.Header.Set("X-User-Auth-Token", s.authToken)
-	req.Header.Set("X-App-Id", s.app_id)
-
-	resp, err := http.DefaultClient.Do(req)
+	bundleURL, _, err := bundle.FindURL(loginPage)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
-	defer resp.Body.Close()
+	bundleJS, err := s.fetch(ctx, s.baseURL+bundleURL)
+	if err != nil {
+		return "", nil, err
+	}
 
-	var searchResponse SearchResponse
-	err = json.NewDecoder(resp.Body).Decode(&searchResponse)
+	info, err := bundle.Parse(loginPage, bundleJS)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
-	return &searchResponse, nil
+	credentials := &AppCredentials{
+		AppID:         info.AppID,
+		Secrets:       info.Secrets,
+		BundleVersion: info.Version,
+		BaseURL:       s.baseURL,
+		FetchedAt:     time.Now(),
+	}
+
+	s.setAppCredentials(credentials)
+	s.saveAppCredentials(credentials)
+
+	return info.AppID, info.Secrets, nil
 }
@@ -0,0 +1,186 @@
+package qobuz
+
+import (
+	"errors"
+	"fmt"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// The tests below are meant to be run with -race.
+
+func TestClientSharedAcrossGoroutines(t *testing.T) {
+	fake := newFakeQobuz(t)
+
+	client, err := NewFromCredentials("user@example.com", "password", fake.options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const rotatedSecret = "abcdefabcdefabcdefabcdefabcdef01"
+
+	var (
+		wg    sync.WaitGroup
+		done  int32
+		calls int32
+		stop  = make(chan struct{})
+		errs  = make(chan error, 8)
+	)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := i; ; j++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				var err error
+				switch j % 3 {
+				case 0:
+					_, err = client.Search("q")
+				case 1:
+					_, err = client.Album("a1")
+				case 2:
+					_, err = client.DownloadFileLink("1", int(types.CD16_44))
+				}
+
+				if err != nil {
+					errs <- err
+					return
+				}
+
+				atomic.AddInt32(&calls, 1)
+			}
+		}(i)
+	}
+
+	waitCalls := func(n int32) {
+		deadline := time.Now().Add(10 * time.Second)
+		for atomic.LoadInt32(&calls) < n && atomic.LoadInt32(&done) == 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	go func() {
+		wg.Wait()
+		atomic.StoreInt32(&done, 1)
+	}()
+
+	// Expire the token and rotate the secret while requests are in flight,
+	// every goroutine must go through the reauth and the secret refresh.
+	waitCalls(50)
+	fake.expireToken()
+	fake.rotateSecret(rotatedSecret)
+	waitCalls(200)
+
+	close(stop)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if calls < 200 {
+		t.Fatalf("only %d calls completed", calls)
+	}
+
+	if client.Secret() != rotatedSecret {
+		t.Errorf("Secret() = %q, want the rotated secret", client.Secret())
+	}
+
+	fake.mu.Lock()
+	scrapes := fake.scrapes
+	fake.mu.Unlock()
+
+	if scrapes != 2 {
+		t.Errorf("bundle scraped %d times, want one refresh shared by every goroutine", scrapes)
+	}
+
+	if client.AuthToken() != fake.currentToken() {
+		t.Errorf("AuthToken() = %q, want %q", client.AuthToken(), fake.currentToken())
+	}
+}
+
+func TestClientsCreatedInParallel(t *testing.T) {
+	fake := newFakeQobuz(t)
+
+	var wg sync.WaitGroup
+	clients := make([]*QobuzClient, 8)
+	errs := make([]error, len(clients))
+
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], errs[i] = NewFromCredentials("user@example.com", "password", fake.options()...)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, client := range clients {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+
+		if client.Secret() != testSecret {
+			t.Errorf("client %d selected secret %q, want %q", i, client.Secret(), testSecret)
+		}
+	}
+}
+
+func TestLoginWithToken(t *testing.T) {
+	fake := newFakeQobuz(t)
+
+	// A cached secret skips the probe: the token must still be checked.
+	store := NewFileAppCredentialsStore(filepath.Join(t.TempDir(), "app_credentials.json"))
+	if err := store.Save(&AppCredentials{
+		AppID:     testAppID,
+		Secrets:   []string{testSecret},
+		Secret:    testSecret,
+		BaseURL:   fake.URL,
+		FetchedAt: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewFromAuthToken(fake.currentToken(), fake.options(WithAppCredentialsStore(store))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user := client.User(); user == nil || user.Id != 1 {
+		t.Errorf("User() = %+v, want the user of the token", user)
+	}
+
+	fake.mu.Lock()
+	scrapes := fake.scrapes
+	fake.mu.Unlock()
+
+	if scrapes != 0 {
+		t.Errorf("bundle scraped %d times, want the cached secret used", scrapes)
+	}
+
+	if _, err := NewFromAuthToken("token-expired", fake.options(WithAppCredentialsStore(store))...); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("NewFromAuthToken with a rejected token: error = %v, want ErrUnauthorized", err)
+	}
+
+	// Some answers are a 200 without a user.
+	fake.handle("/user/login", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"user":null}`)
+	})
+
+	if _, err := NewFromAuthToken(fake.currentToken(), fake.options(WithAppCredentialsStore(store))...); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("NewFromAuthToken without a user in the answer: error = %v, want ErrUnauthorized", err)
+	}
+}
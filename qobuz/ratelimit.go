@@ -0,0 +1,124 @@
+package qobuz
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket safe for concurrent use.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter allows requestsPerSecond on average and bursts of up to
+// burst requests. A requestsPerSecond of 0 or less means no limit: Wait
+// never blocks.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done, and returns how
+// long it waited.
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	if l == nil || l.rate <= 0 {
+		return 0, nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	// Take the token now, possibly going negative, so concurrent callers
+	// queue up behind each other instead of racing for the same refill.
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return time.Since(now), ctx.Err()
+	case <-timer.C:
+		return wait, nil
+	}
+}
+
+// WithRateLimit limits the metadata endpoints (search, album, login...). See
+// NewRateLimiter for the arguments.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(s *QobuzClient) {
+		s.metadataLimiter = NewRateLimiter(requestsPerSecond, burst)
+	}
+}
+
+// WithFileURLRateLimit limits track/getFileUrl separately from metadata.
+func WithFileURLRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(s *QobuzClient) {
+		s.fileURLLimiter = NewRateLimiter(requestsPerSecond, burst)
+	}
+}
+
+// WithRateLimiters shares existing limiters, e.g. between several clients.
+// A nil limiter disables limiting for its endpoints.
+func WithRateLimiters(metadata, fileURL *RateLimiter) Option {
+	return func(s *QobuzClient) {
+		s.metadataLimiter = metadata
+		s.fileURLLimiter = fileURL
+	}
+}
+
+// WithRateLimitObserver registers a callback called after every rate limited
+// request with the time it spent waiting for a token.
+func WithRateLimitObserver(observer func(endpoint string, waited time.Duration)) Option {
+	return func(s *QobuzClient) {
+		s.rateLimitObserver = observer
+	}
+}
+
+func (s *QobuzClient) waitRateLimit(ctx context.Context, endpoint string) error {
+	limiter := s.metadataLimiter
+	if endpoint == "/track/getFileUrl" {
+		limiter = s.fileURLLimiter
+	}
+
+	if limiter == nil {
+		return nil
+	}
+
+	waited, err := limiter.Wait(ctx)
+	if s.rateLimitObserver != nil {
+		s.rateLimitObserver(endpoint, waited)
+	}
+
+	return err
+}
@@ -0,0 +1,171 @@
+package qobuz
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSpacesConcurrentCallers(t *testing.T) {
+	const (
+		rate    = 20
+		callers = 6
+	)
+	interval := time.Second / rate
+
+	limiter := NewRateLimiter(rate, 1)
+	start := time.Now()
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		times []time.Duration
+	)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, err := limiter.Wait(context.Background()); err != nil {
+				t.Error(err)
+			}
+
+			mu.Lock()
+			times = append(times, time.Since(start))
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	// The first caller uses the burst, every other one waits its turn.
+	for i := 1; i < len(times); i++ {
+		if earliest := time.Duration(i)*interval - interval/5; times[i] < earliest {
+			t.Errorf("caller %d went through after %v, want at least %v", i, times[i], earliest)
+		}
+	}
+}
+
+func TestRateLimiterWithoutRateNeverWaits(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		limiter := NewRateLimiter(rate, 1)
+		for i := 0; i < 100; i++ {
+			if waited, err := limiter.Wait(context.Background()); waited != 0 || err != nil {
+				t.Fatalf("rate %v: Wait = %v, %v; want no wait", rate, waited, err)
+			}
+		}
+	}
+}
+
+func TestRateLimiterRefundsCancelledWait(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+
+	if waited, err := limiter.Wait(context.Background()); waited != 0 || err != nil {
+		t.Fatalf("first Wait = %v, %v; want the burst token", waited, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait error = %v, want a deadline exceeded error", err)
+	}
+
+	limiter.mu.Lock()
+	tokens := limiter.tokens
+	limiter.mu.Unlock()
+
+	// Without the refund the bucket would owe a whole token.
+	if tokens < -0.5 {
+		t.Errorf("tokens = %v after a cancelled wait, want the token given back", tokens)
+	}
+}
+
+// drain empties a limiter so the next Wait blocks for about the time it
+// takes to refill n tokens.
+func drain(limiter *RateLimiter, n float64) {
+	limiter.mu.Lock()
+	limiter.tokens = 1 - n
+	limiter.last = time.Now()
+	limiter.mu.Unlock()
+}
+
+func TestFileURLsHaveTheirOwnBucket(t *testing.T) {
+	fake := newFakeQobuz(t)
+
+	metadata, fileURL := NewRateLimiter(1, 1), NewRateLimiter(1, 1)
+	client, err := NewFromCredentials("user@example.com", "password", fake.options(WithRateLimiters(metadata, fileURL))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drain(metadata, 100)
+	drain(fileURL, 0)
+
+	started := time.Now()
+	if _, err := client.DownloadFileLink("1", 6); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(started); elapsed > time.Second/2 {
+		t.Errorf("getFileUrl waited %v for the metadata bucket", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.AlbumContext(ctx, "a1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Album error = %v, want it held back by the empty metadata bucket", err)
+	}
+}
+
+func TestRateLimitObserver(t *testing.T) {
+	fake := newFakeQobuz(t)
+
+	var (
+		mu     sync.Mutex
+		waits  = make(map[string]time.Duration)
+		limits = NewRateLimiter(20, 1)
+	)
+	client, err := NewFromCredentials("user@example.com", "password", fake.options(
+		WithRateLimiters(limits, nil),
+		WithRateLimitObserver(func(endpoint string, waited time.Duration) {
+			mu.Lock()
+			waits[endpoint] = waited
+			mu.Unlock()
+		}),
+	)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two tokens short: about 100ms at 20 requests per second.
+	drain(limits, 2)
+
+	if _, err := client.Album("a1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.DownloadFileLink("1", 6); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if waited := waits["/album/get"]; waited < 80*time.Millisecond || waited > time.Second {
+		t.Errorf("observer got %v for /album/get, want about 100ms", waited)
+	}
+
+	if _, ok := waits["/user/login"]; !ok {
+		t.Error("observer was not called for the login")
+	}
+
+	if _, ok := waits["/track/getFileUrl"]; ok {
+		t.Error("observer was called for an endpoint without a limiter")
+	}
+}
@@ -0,0 +1,82 @@
+package qobuz
+
+import (
+	"context"
+	"errors"
+)
+
+// WithLoginCredentials lets a client created from a token log in again with
+// a password hash once the token expires.
+func WithLoginCredentials(email, passwordHash string) Option {
+	return func(s *QobuzClient) {
+		s.email = email
+		s.passwordHash = passwordHash
+	}
+}
+
+// WithSessionStore makes the client pick up a token refreshed by another
+// process when its own is rejected, and save the tokens it obtains.
+func WithSessionStore(store SessionStore) Option {
+	return func(s *QobuzClient) {
+		s.sessionStore = store
+	}
+}
+
+// WithTokenRefreshHook is called with the new session every time the client
+// reauthenticated on its own.
+func WithTokenRefreshHook(hook func(session *Session)) Option {
+	return func(s *QobuzClient) {
+		s.tokenRefreshHook = hook
+	}
+}
+
+// reauthenticate replaces usedToken after the API rejected it, first with a
+// newer token from the session store, then by logging in again. fromStore
+// tells the caller the token came from the store and was not checked.
+// Concurrent callers share one reauthentication.
+func (s *QobuzClient) reauthenticate(ctx context.Context, usedToken string) (fromStore bool, err error) {
+	s.reauthMu.Lock()
+	defer s.reauthMu.Unlock()
+
+	if s.AuthToken() != usedToken {
+		return false, nil
+	}
+
+	if s.sessionStore != nil {
+		session, err := s.sessionStore.Load()
+		if err == nil && session != nil && session.UserAuthToken != "" && session.UserAuthToken != usedToken {
+			s.mu.Lock()
+			s.authToken = session.UserAuthToken
+			if session.User != nil {
+				s.user = session.User
+			}
+			s.mu.Unlock()
+
+			return true, nil
+		}
+	}
+
+	if s.email == "" || s.passwordHash == "" {
+		return false, errors.New("no credentials to log in again")
+	}
+
+	s.mu.Lock()
+	s.loggedIn = false
+	s.mu.Unlock()
+
+	if err := s.LoginContext(ctx); err != nil {
+		return false, err
+	}
+
+	session := s.Session()
+	if s.sessionStore != nil {
+		// A failure to persist the token must not fail the replayed request.
+		_ = s.sessionStore.Save(session)
+	}
+
+	if s.tokenRefreshHook != nil {
+		s.tokenRefreshHook(session)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,35 @@
+package qobuz
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReauthFallsBackToLoginAfterStaleStoreToken(t *testing.T) {
+	fake := newFakeQobuz(t)
+	store := NewFileSessionStore(filepath.Join(t.TempDir(), "session.json"))
+
+	client, err := NewFromCredentials("user@example.com", "password", fake.options(WithSessionStore(store))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process saved a token that has expired since.
+	if err := store.Save(&Session{UserAuthToken: "token-stale"}); err != nil {
+		t.Fatal(err)
+	}
+	fake.expireToken()
+
+	if _, err := client.Album("a1"); err != nil {
+		t.Fatalf("Album error = %v, want a login after the stale store token", err)
+	}
+
+	if client.AuthToken() != fake.currentToken() {
+		t.Errorf("AuthToken() = %q, want %q", client.AuthToken(), fake.currentToken())
+	}
+
+	session, err := store.Load()
+	if err != nil || session == nil || session.UserAuthToken != fake.currentToken() {
+		t.Errorf("stored session = %+v, %v; want the new token", session, err)
+	}
+}
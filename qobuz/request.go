@@ -0,0 +1,142 @@
+package qobuz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func (s *QobuzClient) newAPIRequest(ctx context.Context, method, endpoint string, params url.Values) (*http.Request, error) {
+	u := s.apiBaseURL + endpoint
+
+	// POST parameters go in the body so credentials never end up in URLs
+	// and proxy logs.
+	var body io.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader(params.Encode())
+	} else if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	s.mu.RLock()
+	appID, authToken := s.app_id, s.authToken
+	s.mu.RUnlock()
+
+	if appID != "" {
+		req.Header.Set("X-App-Id", appID)
+	}
+
+	if authToken != "" {
+		req.Header.Set("X-User-Auth-Token", authToken)
+	}
+
+	return req, nil
+}
+
+// withTimeout applies WithTimeout to one request, body included.
+func (s *QobuzClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+func (s *QobuzClient) do(req *http.Request) (*http.Response, error) {
+	if s.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", s.userAgent)
+	}
+
+	return s.httpClient.Do(req)
+}
+
+// api sends an API request and decodes its JSON answer into v. A request
+// rejected because of an expired token is replayed once after
+// reauthenticate succeeded. If the replay is rejected too and the token came
+// from the session store, that token is stale as well and the client logs in
+// again before a last replay.
+func (s *QobuzClient) api(ctx context.Context, method, endpoint string, params url.Values, v interface{}) error {
+	authToken := s.AuthToken()
+
+	err := s.apiOnce(ctx, method, endpoint, params, v)
+	if authToken == "" || endpoint == "/user/login" || !errors.Is(err, ErrUnauthorized) {
+		return err
+	}
+
+	fromStore, reauthErr := s.reauthenticate(ctx, authToken)
+	if reauthErr != nil {
+		return err
+	}
+
+	authToken = s.AuthToken()
+
+	err = s.apiOnce(ctx, method, endpoint, params, v)
+	if !fromStore || !errors.Is(err, ErrUnauthorized) {
+		return err
+	}
+
+	// The store still holds the token just used, so this logs in again.
+	if _, reauthErr := s.reauthenticate(ctx, authToken); reauthErr != nil {
+		return err
+	}
+
+	return s.apiOnce(ctx, method, endpoint, params, v)
+}
+
+func (s *QobuzClient) apiOnce(ctx context.Context, method, endpoint string, params url.Values, v interface{}) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := s.doRetry(ctx, func() (*http.Request, error) {
+		if err := s.waitRateLimit(ctx, endpoint); err != nil {
+			return nil, err
+		}
+
+		return s.newAPIRequest(ctx, method, endpoint, params)
+	})
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(endpoint, resp)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (s *QobuzClient) fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := s.doRetry(ctx, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("receiving status of %d for url: %s", resp.StatusCode, rawURL)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,61 @@
+package qobuz
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestLoginCredentialsAreSentInTheBody(t *testing.T) {
+	fake := newFakeQobuz(t)
+
+	var queries []string
+	var forms []url.Values
+	fake.handle("/user/login", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+			t.Errorf("login sent as %s %q, want a POST form", r.Method, r.Header.Get("Content-Type"))
+		}
+
+		queries = append(queries, r.URL.RawQuery)
+		forms = append(forms, r.PostForm)
+		fake.login(w, r)
+	})
+
+	client, err := NewFromCredentials("user@example.com", "password", fake.options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewFromAuthToken(client.AuthToken(), fake.options()...); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(forms) != 2 {
+		t.Fatalf("%d logins, want a password and a token login", len(forms))
+	}
+
+	for i, query := range queries {
+		values, _ := url.ParseQuery(query)
+		for _, key := range []string{"email", "password", "user_auth_token"} {
+			if values.Has(key) {
+				t.Errorf("login %d sent %s in the URL: %q", i, key, query)
+			}
+		}
+	}
+
+	if got := forms[0].Get("email"); got != "user@example.com" {
+		t.Errorf("email in the body = %q", got)
+	}
+
+	if got := forms[0].Get("password"); got != HashPassword("password") {
+		t.Errorf("password in the body = %q, want its MD5 hash", got)
+	}
+
+	if got := forms[1].Get("user_auth_token"); got != client.AuthToken() {
+		t.Errorf("user_auth_token in the body = %q, want %q", got, client.AuthToken())
+	}
+}
@@ -0,0 +1,156 @@
+package qobuz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. Network errors and
+// 5xx answers are only retried for idempotent methods, 429 and 503 are
+// retried for every method since the server did not process the request.
+// A Retry-After longer than MaxBackoff is not waited for, the answer is
+// returned instead.
+type RetryPolicy struct {
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  15 * time.Second,
+}
+
+// NoRetry disables retries.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(s *QobuzClient) {
+		s.retryPolicy = policy
+	}
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.MinBackoff <= 0 {
+		return 0
+	}
+
+	d := p.MinBackoff << uint(attempt)
+	if d <= 0 || (p.MaxBackoff > 0 && d > p.MaxBackoff) {
+		d = p.MaxBackoff
+	}
+
+	// Jitter between half and the full backoff so concurrent clients spread out.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// maxRetryAfter is the longest Retry-After honored, longer ones are not
+// retried.
+func (p RetryPolicy) maxRetryAfter() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return DefaultRetryPolicy.MaxBackoff
+	}
+
+	return p.MaxBackoff
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	return false
+}
+
+func shouldRetry(method string, resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+
+		return isIdempotent(method)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return isIdempotent(method)
+	}
+
+	return false
+}
+
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		d := time.Until(date)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+// doRetry sends the request built by newRequest, rebuilding it for every
+// attempt so that bodies and signatures are fresh.
+func (s *QobuzClient) doRetry(ctx context.Context, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	attempts := s.retryPolicy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := s.do(req)
+		if attempt+1 >= attempts || !shouldRetry(req.Method, resp, err) {
+			return resp, err
+		}
+
+		wait, ok := retryAfter(resp)
+		if !ok {
+			wait = s.retryPolicy.backoff(attempt)
+		} else if wait > s.retryPolicy.maxRetryAfter() {
+			// Blocking for hours is worse than failing, leave it to the caller.
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package qobuz
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryAfterIsHonored(t *testing.T) {
+	fake := newFakeQobuz(t)
+
+	var calls int32
+	fake.handle("/album/get", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			apiError(w, http.StatusTooManyRequests, "Too many requests")
+			return
+		}
+
+		fmt.Fprint(w, `{"id":"a1","title":"A"}`)
+	})
+
+	client, err := NewFromCredentials("user@example.com", "password", fake.options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Album("a1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 2 {
+		t.Errorf("album/get called %d times, want 2", calls)
+	}
+}
+
+func TestLongRetryAfterIsNotWaitedFor(t *testing.T) {
+	fake := newFakeQobuz(t)
+
+	var calls int32
+	fake.handle("/album/get", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "86400")
+		apiError(w, http.StatusTooManyRequests, "Too many requests")
+	})
+
+	client, err := NewFromCredentials("user@example.com", "password", fake.options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := client.Album("a1"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Album error = %v, want ErrRateLimited", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > DefaultRetryPolicy.MaxBackoff {
+		t.Errorf("Album took %s", elapsed)
+	}
+
+	if calls != 1 {
+		t.Errorf("album/get called %d times, want 1", calls)
+	}
+}
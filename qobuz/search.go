@@ -0,0 +1,195 @@
+package qobuz
+
+import (
+	"context"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"net/url"
+	"strconv"
+)
+
+// Types of catalog/search results.
+const (
+	SearchAlbums    = "albums"
+	SearchTracks    = "tracks"
+	SearchArtists   = "artists"
+	SearchPlaylists = "playlists"
+	SearchStories   = "stories"
+)
+
+const defaultSearchLimit = 50
+
+// SearchOptions restricts the results to one Type, or every type when empty,
+// and selects the page with Offset and Limit. A zero Limit uses the API
+// default.
+type SearchOptions struct {
+	Type   string
+	Limit  int
+	Offset int
+}
+
+type SearchResponse struct {
+	Query  string `json:"query"`
+	Albums struct {
+		Limit  int           `json:"limit"`
+		Offset int           `json:"offset"`
+		Total  int           `json:"total"`
+		Items  []types.Album `json:"items"`
+	} `json:"albums"`
+	Tracks struct {
+		Limit  int           `json:"limit"`
+		Offset int           `json:"offset"`
+		Total  int           `json:"total"`
+		Items  []types.Track `json:"items"`
+	} `json:"tracks"`
+	Artists struct {
+		Limit  int            `json:"limit"`
+		Offset int            `json:"offset"`
+		Total  int            `json:"total"`
+		Items  []types.Artist `json:"items"`
+	} `json:"artists"`
+	Playlists struct {
+		Limit  int              `json:"limit"`
+		Offset int              `json:"offset"`
+		Total  int              `json:"total"`
+		Items  []types.Playlist `json:"items"`
+	} `json:"playlists"`
+	Stories struct {
+		Limit  int           `json:"limit"`
+		Offset int           `json:"offset"`
+		Total  int           `json:"total"`
+		Items  []interface{} `json:"items"` // TODO: Implement stories
+	} `json:"stories"`
+}
+
+func (s *QobuzClient) Search(query string) (*SearchResponse, error) {
+	return s.SearchContext(context.Background(), query)
+}
+
+func (s *QobuzClient) SearchContext(ctx context.Context, query string) (*SearchResponse, error) {
+	return s.SearchWithOptionsContext(ctx, query, nil)
+}
+
+func (s *QobuzClient) SearchWithOptions(query string, opts *SearchOptions) (*SearchResponse, error) {
+	return s.SearchWithOptionsContext(context.Background(), query, opts)
+}
+
+func (s *QobuzClient) SearchWithOptionsContext(ctx context.Context, query string, opts *SearchOptions) (*SearchResponse, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	if opts != nil {
+		if opts.Type != "" {
+			params.Set("type", opts.Type)
+		}
+		if opts.Limit > 0 {
+			params.Set("limit", strconv.Itoa(opts.Limit))
+		}
+		params.Set("offset", strconv.Itoa(opts.Offset))
+	}
+
+	var searchResponse SearchResponse
+	err := s.api(ctx, "GET", "/catalog/search", params, &searchResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return &searchResponse, nil
+}
+
+// page returns the number of results and the total of the type, or the
+// largest of them all when searchType is empty.
+func (r *SearchResponse) page(searchType string) (count, total int) {
+	pages := map[string][2]int{
+		SearchAlbums:    {len(r.Albums.Items), r.Albums.Total},
+		SearchTracks:    {len(r.Tracks.Items), r.Tracks.Total},
+		SearchArtists:   {len(r.Artists.Items), r.Artists.Total},
+		SearchPlaylists: {len(r.Playlists.Items), r.Playlists.Total},
+		SearchStories:   {len(r.Stories.Items), r.Stories.Total},
+	}
+
+	if searchType != "" {
+		return pages[searchType][0], pages[searchType][1]
+	}
+
+	for _, page := range pages {
+		count = max(count, page[0])
+		total = max(total, page[1])
+	}
+
+	return count, total
+}
+
+// SearchIterator fetches the results of a search one page at a time:
+//
+//	it := client.SearchPages(query, &qobuz.SearchOptions{Type: qobuz.SearchAlbums})
+//	for it.Next() {
+//		albums := it.Page().Albums.Items
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type SearchIterator struct {
+	client *QobuzClient
+	ctx    context.Context
+	query  string
+	opts   SearchOptions
+	page   *SearchResponse
+	more   bool
+	err    error
+}
+
+func (s *QobuzClient) SearchPages(query string, opts *SearchOptions) *SearchIterator {
+	return s.SearchPagesContext(context.Background(), query, opts)
+}
+
+func (s *QobuzClient) SearchPagesContext(ctx context.Context, query string, opts *SearchOptions) *SearchIterator {
+	it := &SearchIterator{client: s, ctx: ctx, query: query, more: true}
+	if opts != nil {
+		it.opts = *opts
+	}
+
+	if it.opts.Limit <= 0 {
+		it.opts.Limit = defaultSearchLimit
+	}
+
+	return it
+}
+
+// Next fetches the next page. It returns false after the last page or on
+// error, see Err.
+func (it *SearchIterator) Next() bool {
+	if !it.more || it.err != nil {
+		return false
+	}
+
+	page, err := it.client.SearchWithOptionsContext(it.ctx, it.query, &it.opts)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	count, total := page.page(it.opts.Type)
+	if count == 0 {
+		it.more = false
+		return false
+	}
+
+	it.page = page
+	it.opts.Offset += it.opts.Limit
+	it.more = it.opts.Offset < total
+
+	return true
+}
+
+// More reports whether Next may return another page.
+func (it *SearchIterator) More() bool {
+	return it.more && it.err == nil
+}
+
+// Page returns the page fetched by the last call to Next.
+func (it *SearchIterator) Page() *SearchResponse {
+	return it.page
+}
+
+func (it *SearchIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,150 @@
+package qobuz
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type searchRequest struct {
+	Type          string
+	Offset, Limit int
+}
+
+// serveSearch makes the fake answer catalog/search with albums out of total,
+// of which only the first available are actually returned. It returns the
+// requests received.
+func serveSearch(fake *fakeQobuz, total, available int) func() []searchRequest {
+	var (
+		mu       sync.Mutex
+		requests []searchRequest
+	)
+
+	fake.handle("/catalog/search", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+
+		mu.Lock()
+		requests = append(requests, searchRequest{Type: q.Get("type"), Offset: offset, Limit: limit})
+		mu.Unlock()
+
+		items := []map[string]string{}
+		for i := offset; i < min(offset+limit, available); i++ {
+			items = append(items, map[string]string{"id": strconv.Itoa(i + 1)})
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"query":  q.Get("query"),
+			"albums": map[string]interface{}{"offset": offset, "limit": limit, "total": total, "items": items},
+			// Other types are always there, with fewer results.
+			"artists": map[string]interface{}{"total": 1, "items": []map[string]int{{"id": 1}}},
+		})
+	})
+
+	return func() []searchRequest {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return requests
+	}
+}
+
+func TestSearchPages(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		opts             *SearchOptions
+		total, available int
+		pages            []int
+		more             []bool
+		offsets          []int
+	}{
+		{"several pages", &SearchOptions{Type: SearchAlbums}, 120, 120, []int{50, 50, 20}, []bool{true, true, false}, []int{0, 50, 100}},
+		{"exact multiple of the limit", &SearchOptions{Type: SearchAlbums, Limit: 25}, 50, 50, []int{25, 25}, []bool{true, false}, []int{0, 25}},
+		{"starting offset", &SearchOptions{Type: SearchAlbums, Offset: 50}, 120, 120, []int{50, 20}, []bool{true, false}, []int{50, 100}},
+		{"total larger than the results", &SearchOptions{Type: SearchAlbums}, 200, 60, []int{50, 10}, []bool{true, true}, []int{0, 50, 100}},
+		{"no results", &SearchOptions{Type: SearchAlbums}, 0, 0, nil, nil, []int{0}},
+		{"default options", nil, 70, 70, []int{50, 20}, []bool{true, false}, []int{0, 50}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fake := newFakeQobuz(t)
+			requested := serveSearch(fake, test.total, test.available)
+
+			client, err := NewFromCredentials("user@example.com", "password", fake.options()...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			it := client.SearchPages("q", test.opts)
+			if !it.More() {
+				t.Error("More() is false before the first page")
+			}
+
+			var pages []int
+			var more []bool
+			for it.Next() {
+				pages = append(pages, len(it.Page().Albums.Items))
+				more = append(more, it.More())
+			}
+
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !equalInts(pages, test.pages) {
+				t.Errorf("pages of %v results, want %v", pages, test.pages)
+			}
+
+			for i := range more {
+				if i < len(test.more) && more[i] != test.more[i] {
+					t.Errorf("More() after page %d = %v, want %v", i, more[i], test.more[i])
+				}
+			}
+
+			if it.More() || it.Next() {
+				t.Error("the iterator goes on after the last page")
+			}
+
+			requests := requested()
+			var offsets []int
+			for _, request := range requests {
+				offsets = append(offsets, request.Offset)
+
+				wantType, wantLimit := "", defaultSearchLimit
+				if test.opts != nil {
+					wantType = test.opts.Type
+					if test.opts.Limit > 0 {
+						wantLimit = test.opts.Limit
+					}
+				}
+
+				if request.Type != wantType || request.Limit != wantLimit {
+					t.Errorf("request %+v, want type %q and limit %d", request, wantType, wantLimit)
+				}
+			}
+
+			if !equalInts(offsets, test.offsets) {
+				t.Errorf("requested offsets %v, want %v", offsets, test.offsets)
+			}
+		})
+	}
+}
+
+func TestSearchPagesError(t *testing.T) {
+	fake := newFakeQobuz(t)
+	fake.handle("/catalog/search", func(w http.ResponseWriter, r *http.Request) {
+		apiError(w, http.StatusBadRequest, "Invalid query")
+	})
+
+	client, err := NewFromCredentials("user@example.com", "password", fake.options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it := client.SearchPages("q", nil)
+	if it.Next() || it.Err() == nil || it.More() {
+		t.Fatalf("Next on a failing search = true, or no error (%v), or More()", it.Err())
+	}
+}
@@ -0,0 +1,84 @@
+package qobuz
+
+import (
+	"context"
+	"errors"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"net/http"
+)
+
+// secretProbeTrackID is a long-lived catalog track used to check signatures.
+const secretProbeTrackID = "5966783"
+
+// Secret returns the app secret selected by validation, or an empty string
+// if none has been selected yet.
+func (s *QobuzClient) Secret() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.secret
+}
+
+func (s *QobuzClient) validSecret(ctx context.Context) (string, error) {
+	s.mu.RLock()
+	secret := s.secret
+	s.mu.RUnlock()
+
+	if secret != "" {
+		return secret, nil
+	}
+
+	if err := s.selectSecret(ctx); err != nil {
+		return "", err
+	}
+
+	return s.Secret(), nil
+}
+
+// selectSecret signs a probe track/getFileUrl request with each extracted
+// secret and keeps the first one the API accepts. It needs an auth token.
+func (s *QobuzClient) selectSecret(ctx context.Context) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	return s.selectSecretLocked(ctx)
+}
+
+func (s *QobuzClient) selectSecretLocked(ctx context.Context) error {
+	s.mu.RLock()
+	selected, credentials := s.secret, s.appCredentials
+	s.mu.RUnlock()
+
+	if selected != "" {
+		return nil
+	}
+
+	if credentials == nil || len(credentials.Secrets) == 0 {
+		return ErrNoValidSecret
+	}
+
+	for _, candidate := range credentials.Secrets {
+		_, err := s.fileURL(ctx, secretProbeTrackID, int(types.MP3), candidate)
+		if errors.Is(err, ErrInvalidSignature) {
+			continue
+		}
+
+		// Only a file URL, or a 200 without one because of restrictions,
+		// proves the signature was checked. Rate limiting, server errors or
+		// a missing probe track say nothing about the secret.
+		var apiErr *APIError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusOK) {
+			return err
+		}
+
+		validated := *credentials
+		validated.Secret = candidate
+
+		s.setAppCredentials(&validated)
+		s.saveAppCredentials(&validated)
+
+		return nil
+	}
+
+	return ErrNoValidSecret
+}
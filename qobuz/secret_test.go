@@ -0,0 +1,83 @@
+package qobuz
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelectSecretSkipsRejectedCandidates(t *testing.T) {
+	fake := newFakeQobuz(t)
+
+	store := NewFileAppCredentialsStore(filepath.Join(t.TempDir(), "app_credentials.json"))
+	client, err := NewFromCredentials("user@example.com", "password", fake.options(WithAppCredentialsStore(store))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Secret() != testSecret {
+		t.Errorf("Secret() = %q, want %q", client.Secret(), testSecret)
+	}
+
+	credentials, err := store.Load()
+	if err != nil || credentials == nil || credentials.Secret != testSecret {
+		t.Errorf("stored credentials = %+v, %v; want the validated secret", credentials, err)
+	}
+}
+
+func TestSelectSecretAcceptsRestrictedProbe(t *testing.T) {
+	fake := newFakeQobuz(t)
+	fake.handle("/track/getFileUrl", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"track_id":5966783,"restrictions":[{"code":"TrackRestrictedByRightHolders"}]}`)
+	})
+
+	client, err := NewFromCredentials("user@example.com", "password", fake.options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Secret() != testWrongSecret {
+		t.Errorf("Secret() = %q, want the first candidate %q", client.Secret(), testWrongSecret)
+	}
+}
+
+func TestSelectSecretDoesNotCacheUncheckedSecret(t *testing.T) {
+	for _, test := range []struct {
+		status int
+		target error
+	}{
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusInternalServerError, nil},
+	} {
+		t.Run(http.StatusText(test.status), func(t *testing.T) {
+			fake := newFakeQobuz(t)
+			fake.handle("/track/getFileUrl", func(w http.ResponseWriter, r *http.Request) {
+				apiError(w, test.status, http.StatusText(test.status))
+			})
+
+			store := NewFileAppCredentialsStore(filepath.Join(t.TempDir(), "app_credentials.json"))
+			_, err := NewFromCredentials("user@example.com", "password", fake.options(WithAppCredentialsStore(store), WithRetryPolicy(NoRetry))...)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != test.status {
+				t.Fatalf("error = %v, want the %d answer", err, test.status)
+			}
+
+			if test.target != nil && !errors.Is(err, test.target) {
+				t.Errorf("error = %v, want %v", err, test.target)
+			}
+
+			credentials, err := store.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if credentials != nil && credentials.Secret != "" {
+				t.Errorf("cached secret %q that was never accepted", credentials.Secret)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package qobuz
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testAppID       = "123456789"
+	testSecret      = "0123456789abcdef0123456789abcdef"
+	testWrongSecret = "ffffffffffffffffffffffffffffffff"
+)
+
+// fakeQobuz serves the parts of the web player and the API the client uses.
+// The bundle lists a wrong secret before the valid one, like the real one
+// sometimes does.
+type fakeQobuz struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	secret   string
+	token    string
+	tokens   int
+	logins   int
+	scrapes  int
+	handlers map[string]http.HandlerFunc
+}
+
+func newFakeQobuz(t *testing.T) *fakeQobuz {
+	f := &fakeQobuz{
+		secret:   testSecret,
+		token:    "token-1",
+		tokens:   1,
+		handlers: make(map[string]http.HandlerFunc),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<script src="/resources/7.1.0-b011/bundle.js"></script>`)
+	})
+	mux.HandleFunc("/resources/7.1.0-b011/bundle.js", f.bundle)
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		endpoint := strings.TrimPrefix(r.URL.Path, "/api")
+
+		f.mu.Lock()
+		handler := f.handlers[endpoint]
+		f.mu.Unlock()
+
+		switch {
+		case handler != nil:
+			handler(w, r)
+		case endpoint == "/user/login":
+			f.login(w, r)
+		case endpoint == "/track/getFileUrl":
+			f.fileURL(w, r)
+		case !f.authorized(r):
+			apiError(w, http.StatusUnauthorized, "User authentication is required.")
+		case endpoint == "/catalog/search":
+			fmt.Fprint(w, `{"query":"q","albums":{"total":1,"items":[{"id":"a1","title":"A"}]}}`)
+		case endpoint == "/album/get":
+			fmt.Fprint(w, `{"id":"a1","title":"A","tracks":{"total":1,"items":[{"id":1,"title":"T"}]}}`)
+		default:
+			apiError(w, http.StatusNotFound, "No route")
+		}
+	})
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+// handle replaces the default answer of an API endpoint.
+func (f *fakeQobuz) handle(endpoint string, handler http.HandlerFunc) {
+	f.mu.Lock()
+	f.handlers[endpoint] = handler
+	f.mu.Unlock()
+}
+
+// options point a client at the fake and keep the tests off the disk.
+func (f *fakeQobuz) options(opts ...Option) []Option {
+	return append([]Option{
+		WithBaseURL(f.URL),
+		WithAPIBaseURL(f.URL + "/api"),
+		WithAppCredentialsStore(nil),
+	}, opts...)
+}
+
+// expireToken makes the API reject the current token, the next password
+// login gets a new one.
+func (f *fakeQobuz) expireToken() {
+	f.mu.Lock()
+	f.tokens++
+	f.token = fmt.Sprintf("token-%d", f.tokens)
+	f.mu.Unlock()
+}
+
+// rotateSecret makes the API only accept signatures made with secret, and
+// the bundle list it.
+func (f *fakeQobuz) rotateSecret(secret string) {
+	f.mu.Lock()
+	f.secret = secret
+	f.mu.Unlock()
+}
+
+func (f *fakeQobuz) currentToken() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.token
+}
+
+func (f *fakeQobuz) authorized(r *http.Request) bool {
+	return r.Header.Get("X-User-Auth-Token") == f.currentToken()
+}
+
+func (f *fakeQobuz) bundle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.scrapes++
+	secret := f.secret
+	f.mu.Unlock()
+
+	encode := func(secret string) (string, string, string) {
+		encoded := base64.StdEncoding.EncodeToString([]byte(secret)) + strings.Repeat("x", 44)
+		return encoded[:10], encoded[10:30], encoded[30:]
+	}
+
+	seed, info, extras := encode(secret)
+	wrongSeed, wrongInfo, wrongExtras := encode(testWrongSecret)
+
+	// The web player tries the second timezone first, so the wrong secret
+	// comes first.
+	fmt.Fprintf(w, `production:{api:{appId:"%s",appSecret:"abcdefabcdefabcdefabcdefabcdefab"}}`+
+		`a.initialSeed("%s",window.utimezone.berlin)b.initialSeed("%s",window.utimezone.london)`+
+		`name:"Europe/London",info:"%s",extras:"%s"name:"Europe/Berlin",info:"%s",extras:"%s"`,
+		testAppID, seed, wrongSeed, wrongInfo, wrongExtras, info, extras)
+}
+
+func (f *fakeQobuz) login(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	f.mu.Lock()
+	f.logins++
+	token := f.token
+	f.mu.Unlock()
+
+	// Only the body counts, credentials must never be sent in the URL.
+	if r.PostForm.Get("password") == "" && r.PostForm.Get("user_auth_token") != token {
+		apiError(w, http.StatusUnauthorized, "User authentication is required.")
+		return
+	}
+
+	fmt.Fprintf(w, `{"user":{"id":1,"credential":{"parameters":{"lossy_streaming":true,"lossless_streaming":true}}},"user_auth_token":"%s"}`, token)
+}
+
+func (f *fakeQobuz) fileURL(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	f.mu.Lock()
+	secret := f.secret
+	f.mu.Unlock()
+
+	sum := md5.Sum([]byte("trackgetFileUrlformat_id" + q.Get("format_id") + "intentstreamtrack_id" + q.Get("track_id") + q.Get("request_ts") + secret))
+	if hex.EncodeToString(sum[:]) != q.Get("request_sig") {
+		apiError(w, http.StatusBadRequest, "Invalid Request Signature parameter (request_sig)")
+		return
+	}
+
+	if !f.authorized(r) {
+		apiError(w, http.StatusUnauthorized, "User authentication is required.")
+		return
+	}
+
+	fmt.Fprintf(w, `{"track_id":%s,"url":"%s/file/%s","mime_type":"audio/flac","format_id":%s}`, q.Get("track_id"), f.URL, q.Get("track_id"), q.Get("format_id"))
+}
+
+func apiError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"status":"error","code":%d,"message":"%s"}`, status, message)
+}
@@ -0,0 +1,108 @@
+package qobuz
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Session is what a successful login leaves behind. Its token can be given
+// to NewFromAuthToken to skip the password login on the next run.
+type Session struct {
+	UserAuthToken string      `json:"user_auth_token"`
+	User          *types.User `json:"user"`
+	SavedAt       time.Time   `json:"saved_at"`
+}
+
+// SessionStore persists a Session. Load returns nil and no error when
+// nothing has been stored yet.
+type SessionStore interface {
+	Load() (*Session, error)
+	Save(session *Session) error
+	Clear() error
+}
+
+// FileSessionStore keeps the session in a JSON file only readable by the
+// current user.
+type FileSessionStore struct {
+	Path string
+}
+
+func NewFileSessionStore(path string) *FileSessionStore {
+	return &FileSessionStore{Path: path}
+}
+
+func (f *FileSessionStore) Load() (*Session, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (f *FileSessionStore) Save(session *Session) error {
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0700); err != nil {
+		return err
+	}
+
+	// WriteFile keeps the mode of an existing file, chmod it in case it was
+	// created with broader permissions.
+	if err := os.WriteFile(f.Path, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Chmod(f.Path, 0600)
+}
+
+func (f *FileSessionStore) Clear() error {
+	err := os.Remove(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *QobuzClient) AuthToken() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.authToken
+}
+
+// User returns the profile of the logged in user, nil before a login.
+func (s *QobuzClient) User() *types.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.user
+}
+
+// Session returns the current token and user, ready to be saved in a
+// SessionStore.
+func (s *QobuzClient) Session() *Session {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return &Session{
+		UserAuthToken: s.authToken,
+		User:          s.user,
+		SavedAt:       time.Now(),
+	}
+}
@@ -0,0 +1,32 @@
+package qobuz
+
+import "github.com/szerookii/goquobuz/qobuz/types"
+
+// MaxAllowedQuality returns the best format the logged in user can stream.
+// Without a user profile it returns HiRes24_192 and lets the API downgrade.
+func (s *QobuzClient) MaxAllowedQuality() types.Quality {
+	user := s.User()
+	if user == nil {
+		return types.HiRes24_192
+	}
+
+	return user.MaxQuality()
+}
+
+func (s *QobuzClient) CanStream(quality types.Quality) bool {
+	user := s.User()
+	if user == nil {
+		return false
+	}
+
+	return user.CanStream(quality)
+}
+
+func (s *QobuzClient) SubscriptionStatus() types.SubscriptionStatus {
+	user := s.User()
+	if user == nil {
+		return types.SubscriptionStatus{}
+	}
+
+	return user.SubscriptionStatus()
+}
@@ -0,0 +1,46 @@
+package types
+
+type Artist struct {
+	Id                           int    `json:"id"`
+	Name                         string `json:"name"`
+	Slug                         string `json:"slug"`
+	Picture                      string `json:"picture"`
+	AlbumsCount                  int    `json:"albums_count"`
+	AlbumsAsPrimaryArtistCount   int    `json:"albums_as_primary_artist_count"`
+	AlbumsAsPrimaryComposerCount int    `json:"albums_as_primary_composer_count"`
+	Image                        struct {
+		Small      string `json:"small"`
+		Medium     string `json:"medium"`
+		Large      string `json:"large"`
+		Extralarge string `json:"extralarge"`
+		Mega       string `json:"mega"`
+	} `json:"image"`
+	Biography struct {
+		Summary  string `json:"summary"`
+		Content  string `json:"content"`
+		Source   string `json:"source"`
+		Language string `json:"language"`
+	} `json:"biography"`
+	SimilarArtistIds []int `json:"similar_artist_ids"`
+
+	// Albums, Playlists and TracksAppearsOn are only set when requested as
+	// extras of artist/get.
+	Albums struct {
+		Offset int     `json:"offset"`
+		Limit  int     `json:"limit"`
+		Total  int     `json:"total"`
+		Items  []Album `json:"items"`
+	} `json:"albums"`
+	Playlists struct {
+		Offset int        `json:"offset"`
+		Limit  int        `json:"limit"`
+		Total  int        `json:"total"`
+		Items  []Playlist `json:"items"`
+	} `json:"playlists"`
+	TracksAppearsOn struct {
+		Offset int     `json:"offset"`
+		Limit  int     `json:"limit"`
+		Total  int     `json:"total"`
+		Items  []Track `json:"items"`
+	} `json:"tracks_appears_on"`
+}
@@ -0,0 +1,36 @@
+package types
+
+type Playlist struct {
+	Id              int      `json:"id"`
+	Name            string   `json:"name"`
+	Description     string   `json:"description"`
+	Duration        int      `json:"duration"`
+	TracksCount     int      `json:"tracks_count"`
+	UsersCount      int      `json:"users_count"`
+	IsPublic        bool     `json:"is_public"`
+	IsCollaborative bool     `json:"is_collaborative"`
+	PublicAt        int      `json:"public_at"`
+	CreatedAt       int      `json:"created_at"`
+	UpdatedAt       int      `json:"updated_at"`
+	Images          []string `json:"images"`
+	Images150       []string `json:"images150"`
+	Images300       []string `json:"images300"`
+	ImageRectangle  []string `json:"image_rectangle"`
+	Owner           struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"owner"`
+	Genres []struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	} `json:"genres"`
+
+	// Tracks is only set by playlist/get.
+	Tracks struct {
+		Offset int     `json:"offset"`
+		Limit  int     `json:"limit"`
+		Total  int     `json:"total"`
+		Items  []Track `json:"items"`
+	} `json:"tracks"`
+}
@@ -8,3 +8,18 @@ const (
 	HiRes24_96  Quality = 7
 	HiRes24_192 Quality = 27
 )
+
+func (q Quality) String() string {
+	switch q {
+	case MP3:
+		return "MP3 320kbps"
+	case CD16_44:
+		return "CD 16-Bit / 44.1 kHz"
+	case HiRes24_96:
+		return "Hi-Res 24-Bit / 96 kHz"
+	case HiRes24_192:
+		return "Hi-Res 24-Bit / 192 kHz"
+	}
+
+	return "unknown quality"
+}
@@ -0,0 +1,70 @@
+package types
+
+import "time"
+
+type SubscriptionStatus struct {
+	Offer     string
+	Active    bool
+	Canceled  bool
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+// formatGroups maps the included_format_group_ids of a credential to the
+// best format of each group.
+var formatGroups = map[int]Quality{
+	1: MP3,
+	2: CD16_44,
+	3: HiRes24_96,
+	4: HiRes24_192,
+}
+
+// MaxQuality returns the best format the user's credential can stream, or 0
+// when it cannot stream at all. included_format_group_ids is used when it
+// lists known groups, since it tells 24/96 from 24/192 offers, the
+// *_streaming flags otherwise.
+func (u *User) MaxQuality() Quality {
+	parameters := u.Credential.Parameters
+
+	var maxQuality Quality
+	for _, id := range parameters.IncludedFormatGroupIds {
+		if quality, ok := formatGroups[id]; ok && quality > maxQuality {
+			maxQuality = quality
+		}
+	}
+
+	if maxQuality != 0 {
+		return maxQuality
+	}
+
+	switch {
+	case parameters.HiresStreaming:
+		return HiRes24_192
+	case parameters.LosslessStreaming:
+		return CD16_44
+	case parameters.LossyStreaming:
+		return MP3
+	}
+
+	return 0
+}
+
+func (u *User) CanStream(quality Quality) bool {
+	maxQuality := u.MaxQuality()
+	return maxQuality != 0 && quality <= maxQuality
+}
+
+func (u *User) SubscriptionStatus() SubscriptionStatus {
+	status := SubscriptionStatus{
+		Offer:    u.Subscription.Offer,
+		Canceled: u.Subscription.IsCanceled,
+	}
+
+	status.StartDate, _ = time.Parse("2006-01-02", u.Subscription.StartDate)
+	status.EndDate, _ = time.Parse("2006-01-02", u.Subscription.EndDate)
+
+	// The end date is the last day covered by the subscription.
+	status.Active = u.Subscription.Offer != "" && (status.EndDate.IsZero() || time.Now().Before(status.EndDate.AddDate(0, 0, 1)))
+
+	return status
+}
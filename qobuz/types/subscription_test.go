@@ -0,0 +1,37 @@
+package types
+
+import "testing"
+
+func TestMaxQuality(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		groups   []int
+		lossy    bool
+		lossless bool
+		hires    bool
+		want     Quality
+	}{
+		{name: "no streaming", want: 0},
+		{name: "lossy flag", lossy: true, want: MP3},
+		{name: "lossless flag", lossy: true, lossless: true, want: CD16_44},
+		{name: "hires flag", lossy: true, lossless: true, hires: true, want: HiRes24_192},
+		{name: "mp3 group", groups: []int{1}, lossy: true, lossless: true, want: MP3},
+		{name: "cd groups", groups: []int{1, 2}, want: CD16_44},
+		{name: "24/96 groups", groups: []int{1, 2, 3}, hires: true, want: HiRes24_96},
+		{name: "24/192 groups", groups: []int{4, 1, 2, 3}, want: HiRes24_192},
+		{name: "unknown groups", groups: []int{42}, lossy: true, lossless: true, want: CD16_44},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			user := &User{}
+			parameters := &user.Credential.Parameters
+			parameters.IncludedFormatGroupIds = test.groups
+			parameters.LossyStreaming = test.lossy
+			parameters.LosslessStreaming = test.lossless
+			parameters.HiresStreaming = test.hires
+
+			if got := user.MaxQuality(); got != test.want {
+				t.Errorf("MaxQuality() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -163,6 +163,8 @@ type Album struct {
 	StreamableAt        int           `json:"streamable_at"`
 	Hires               bool          `json:"hires"`
 	HiresStreamable     bool          `json:"hires_streamable"`
+	ProductType         string        `json:"product_type"`
+	ReleaseType         string        `json:"release_type"`
 }
 
 type FullAlbum struct {
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/huh/spinner"
+	"github.com/szerookii/goquobuz/qobuz"
+)
+
+// loadMore is the option value asking for the next page of results.
+const loadMore = -1
+
+// searchSelect describes the selection list of one type of search results.
+type searchSelect[T any] struct {
+	searchType  string
+	noun        string
+	title       string
+	description string
+	items       func(*qobuz.SearchResponse) []T
+	label       func(T) string
+}
+
+// selectSearchResult searches query one page at a time and lets the user pick
+// a result, or load the next page. It returns nil when nothing was picked.
+func selectSearchResult[T any](ctx context.Context, client *qobuz.QobuzClient, query string, list searchSelect[T]) *T {
+	it := client.SearchPagesContext(ctx, query, &qobuz.SearchOptions{Type: list.searchType})
+
+	var results []T
+	for {
+		if err := spinner.New().Title("Searching for " + list.noun + "...").Action(func() {
+			if it.Next() {
+				results = append(results, list.items(it.Page())...)
+			}
+		}).Run(); err != nil {
+			return nil
+		}
+
+		if err := it.Err(); err != nil {
+			fmt.Println("Failed to search for "+list.noun+":", err)
+			return nil
+		}
+
+		if len(results) == 0 {
+			fmt.Println("No " + list.noun + " found.")
+			return nil
+		}
+
+		var options []huh.Option[int]
+		for i, result := range results {
+			options = append(options, huh.NewOption[int](list.label(result), i))
+		}
+
+		if it.More() {
+			options = append(options, huh.NewOption[int](fmt.Sprintf("Load more... (%d shown)", len(results)), loadMore))
+		}
+
+		var selected int
+		if err := huh.NewSelect[int]().Title(list.title).Description(list.description).Options(options...).Value(&selected).Run(); err != nil {
+			return nil
+		}
+
+		if selected != loadMore {
+			return &results[selected]
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// setting is a config field that can be overridden. Precedence, highest
+// first: command-line flag, GOQOBUZ_* environment variable, config file,
+// default.
+type setting struct {
+	name   string
+	usage  string
+	secret bool
+	get    func(config *Config, profile *Profile) string
+	set    func(config *Config, profile *Profile, value string) error
+}
+
+var settings = []setting{
+	{
+		name:  "email",
+		usage: "Qobuz account email",
+		get:   func(_ *Config, p *Profile) string { return p.Email },
+		set:   func(_ *Config, p *Profile, v string) error { p.Email = v; return nil },
+	},
+	{
+		name:   "password",
+		usage:  "Qobuz account password",
+		secret: true,
+		get:    func(_ *Config, p *Profile) string { return p.Password },
+		set:    func(_ *Config, p *Profile, v string) error { p.Password = v; return nil },
+	},
+	{
+		name:   "password-hash",
+		usage:  "MD5 hash of the Qobuz account password",
+		secret: true,
+		get:    func(_ *Config, p *Profile) string { return p.PasswordHash },
+		set:    func(_ *Config, p *Profile, v string) error { p.PasswordHash = v; return nil },
+	},
+	{
+		name:   "token",
+		usage:  "Qobuz user auth token",
+		secret: true,
+		get:    func(_ *Config, p *Profile) string { return p.Token },
+		set:    func(_ *Config, p *Profile, v string) error { p.Token = v; return nil },
+	},
+	{
+		name:  "download-folder",
+		usage: "folder downloads are written to",
+		get:   func(_ *Config, p *Profile) string { return p.DownloadFolder },
+		set:   func(_ *Config, p *Profile, v string) error { p.DownloadFolder = v; return nil },
+	},
+	{
+		name:  "quality",
+		usage: "preferred quality: mp3, cd, hires96, hires192 or a format ID",
+		get: func(_ *Config, p *Profile) string {
+			if p.Quality == 0 {
+				return ""
+			}
+			return strconv.Itoa(int(p.Quality))
+		},
+		set: func(_ *Config, p *Profile, v string) error {
+			quality, err := parseQuality(v)
+			p.Quality = quality
+			return err
+		},
+	},
+	{
+		name:  "naming-template",
+		usage: "file naming template, e.g. {track_number} - {title}",
+		get:   func(_ *Config, p *Profile) string { return p.NamingTemplate },
+		set:   func(_ *Config, p *Profile, v string) error { p.NamingTemplate = v; return nil },
+	},
+	{
+		name:  "concurrency",
+		usage: "number of album tracks downloaded at the same time",
+		get: func(_ *Config, p *Profile) string {
+			if p.Concurrency == 0 {
+				return ""
+			}
+			return strconv.Itoa(p.Concurrency)
+		},
+		set: func(_ *Config, p *Profile, v string) error {
+			concurrency, err := strconv.Atoi(v)
+			if err != nil || concurrency < 1 {
+				return fmt.Errorf("invalid concurrency %q", v)
+			}
+			p.Concurrency = concurrency
+			return nil
+		},
+	},
+	{
+		name:  "app-id",
+		usage: "Qobuz app ID, skips scraping the web player with app-secret",
+		get:   func(c *Config, _ *Profile) string { return c.AppID },
+		set:   func(c *Config, _ *Profile, v string) error { c.AppID = v; return nil },
+	},
+	{
+		name:   "app-secret",
+		usage:  "Qobuz app secret",
+		secret: true,
+		get:    func(c *Config, _ *Profile) string { return c.AppSecret },
+		set:    func(c *Config, _ *Profile, v string) error { c.AppSecret = v; return nil },
+	},
+}
+
+func (s setting) env() string {
+	return "GOQOBUZ_" + strings.ToUpper(strings.ReplaceAll(s.name, "-", "_"))
+}
+
+func parseQuality(value string) (types.Quality, error) {
+	switch strings.ToLower(value) {
+	case "mp3":
+		return types.MP3, nil
+	case "cd":
+		return types.CD16_44, nil
+	case "hires96":
+		return types.HiRes24_96, nil
+	case "hires192", "hires":
+		return types.HiRes24_192, nil
+	}
+
+	id, err := strconv.Atoi(value)
+	if err == nil {
+		switch quality := types.Quality(id); quality {
+		case 0, types.MP3, types.CD16_44, types.HiRes24_96, types.HiRes24_192:
+			return quality, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid quality %q", value)
+}
+
+// registerSettingFlags adds one flag per setting to flags and returns their
+// values.
+func registerSettingFlags(flags *flag.FlagSet) map[string]*string {
+	values := make(map[string]*string)
+	for _, s := range settings {
+		values[s.name] = flags.String(s.name, "", s.usage+" (env "+s.env()+")")
+	}
+
+	return values
+}
+
+// trailingFlag returns the first of args, the arguments left after parsing,
+// that looks like a flag. The flag package stops at the command, so a flag
+// after it would be silently ignored.
+func trailingFlag(args []string) (string, bool) {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		if len(arg) > 1 && strings.HasPrefix(arg, "-") {
+			return arg, true
+		}
+	}
+
+	return "", false
+}
+
+// effectiveConfig returns copies of config and profile with the environment
+// and flag overrides applied, and where every setting came from. The copies
+// are never written back to the config file.
+func effectiveConfig(config *Config, profile *Profile, flags *flag.FlagSet, flagValues map[string]*string) (*Config, *Profile, map[string]string, error) {
+	effective, effectiveProfile := *config, *profile
+	sources := make(map[string]string)
+
+	setFlags := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+
+	for _, s := range settings {
+		sources[s.name] = "config"
+		if s.get(&effective, &effectiveProfile) == "" {
+			sources[s.name] = "default"
+		}
+
+		value, source := "", ""
+		if setFlags[s.name] {
+			value, source = *flagValues[s.name], "flag"
+		} else if env, ok := os.LookupEnv(s.env()); ok {
+			value, source = env, "env"
+		} else {
+			continue
+		}
+
+		if err := s.set(&effective, &effectiveProfile, value); err != nil {
+			return nil, nil, nil, fmt.Errorf("%s: %v", source, err)
+		}
+		sources[s.name] = source
+	}
+
+	return &effective, &effectiveProfile, sources, nil
+}
+
+// showConfig prints the effective settings with secrets masked.
+func showConfig(name string, config *Config, profile *Profile, sources map[string]string) {
+	fmt.Println("config =", configPath)
+	fmt.Println("profile =", name)
+	for _, s := range settings {
+		value := s.get(config, profile)
+		if s.secret && value != "" {
+			value = "********"
+		}
+
+		fmt.Printf("%s = %s (%s)\n", s.name, value, sources[s.name])
+	}
+}
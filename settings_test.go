@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"github.com/szerookii/goquobuz/qobuz/types"
+	"os"
+	"testing"
+)
+
+func TestEffectiveConfig(t *testing.T) {
+	// Start from a clean environment, t.Setenv restores it afterwards.
+	for _, s := range settings {
+		t.Setenv(s.env(), "")
+		os.Unsetenv(s.env())
+	}
+
+	t.Setenv("GOQOBUZ_QUALITY", "cd")
+	t.Setenv("GOQOBUZ_DOWNLOAD_FOLDER", "/env/music")
+	t.Setenv("GOQOBUZ_CONCURRENCY", "4")
+
+	flags := flag.NewFlagSet("goqobuz", flag.ContinueOnError)
+	values := registerSettingFlags(flags)
+	if err := flags.Parse([]string{"--quality", "hires192", "--email", "flag@example.com", "config", "show"}); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{AppID: "123456789"}
+	profile := &Profile{Email: "file@example.com", DownloadFolder: "/file/music", Quality: types.MP3}
+
+	effective, effectiveProfile, sources, err := effectiveConfig(config, profile, flags, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if effectiveProfile.Quality != types.HiRes24_192 || effectiveProfile.Email != "flag@example.com" {
+		t.Errorf("flags did not win: quality %d, email %q", effectiveProfile.Quality, effectiveProfile.Email)
+	}
+
+	if effectiveProfile.DownloadFolder != "/env/music" || effectiveProfile.Concurrency != 4 {
+		t.Errorf("the environment did not win over the file: folder %q, concurrency %d", effectiveProfile.DownloadFolder, effectiveProfile.Concurrency)
+	}
+
+	if effective.AppID != "123456789" {
+		t.Errorf("AppID = %q, want the file's", effective.AppID)
+	}
+
+	for name, want := range map[string]string{
+		"quality":         "flag",
+		"email":           "flag",
+		"download-folder": "env",
+		"concurrency":     "env",
+		"app-id":          "config",
+		"naming-template": "default",
+		"token":           "default",
+	} {
+		if sources[name] != want {
+			t.Errorf("source of %s = %q, want %q", name, sources[name], want)
+		}
+	}
+
+	if profile.Quality != types.MP3 || profile.Email != "file@example.com" || profile.DownloadFolder != "/file/music" {
+		t.Errorf("the stored profile was modified: %+v", profile)
+	}
+
+	t.Setenv("GOQOBUZ_CONCURRENCY", "none")
+	if _, _, _, err := effectiveConfig(config, profile, flags, values); err == nil {
+		t.Error("an invalid environment value was accepted")
+	}
+}
+
+func TestTrailingFlag(t *testing.T) {
+	for _, test := range []struct {
+		args []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"config", "show"}, ""},
+		{[]string{"config", "show", "--profile", "studio"}, "--profile"},
+		{[]string{"profile", "add", "x", "-email=a@example.com"}, "-email=a@example.com"},
+		{[]string{"profile", "add", "-"}, ""},
+		{[]string{"profile", "add", "--", "-x"}, ""},
+	} {
+		got, ok := trailingFlag(test.args)
+		if got != test.want || ok != (test.want != "") {
+			t.Errorf("trailingFlag(%q) = %q, %v; want %q", test.args, got, ok, test.want)
+		}
+	}
+}